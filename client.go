@@ -2,6 +2,7 @@ package flaro
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
@@ -19,20 +20,28 @@ const (
 
 // Client represents the Flaro API client
 type Client struct {
-	httpClient *http.Client
-	baseURL    string
-	apiKey     string
+	httpClient    *http.Client
+	baseURL       string
+	apiKey        string
+	profiles      *ProfileCache
+	clock         Clock
+	globalSends   idempotencyKeys
+	profileLimits ProfileLimits
 }
 
 // NewClient creates a new Flaro API client with the provided API key
 func NewClient(apiKey string) *Client {
-	return &Client{
+	c := &Client{
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		baseURL: BaseURL,
-		apiKey:  apiKey,
+		baseURL:  BaseURL,
+		apiKey:   apiKey,
+		profiles: NewProfileCache(DefaultProfileCacheSize, DefaultProfileCacheTTL),
+		clock:    systemClock{},
 	}
+	c.profiles.now = c.now
+	return c
 }
 
 // NewClientFromEnv creates a new Flaro API client using API key from environment variable
@@ -46,13 +55,17 @@ func NewClientFromEnv() (*Client, error) {
 
 // NewClientWithOptions creates a new Flaro API client with custom options
 func NewClientWithOptions(baseURL, apiKey string) *Client {
-	return &Client{
+	c := &Client{
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		baseURL: baseURL,
-		apiKey:  apiKey,
+		baseURL:  baseURL,
+		apiKey:   apiKey,
+		profiles: NewProfileCache(DefaultProfileCacheSize, DefaultProfileCacheTTL),
+		clock:    systemClock{},
 	}
+	c.profiles.now = c.now
+	return c
 }
 
 // generateCodeVerifier makes a high-entropy random string (43–128 chars)
@@ -80,6 +93,17 @@ func (c *Client) codeChallengeFromVerifier(verifier string) string {
 	return base64.RawURLEncoding.EncodeToString(h[:]) // no padding
 }
 
+// httpClientFor returns the HTTP client to use for a request bound to ctx. A caller supplied
+// deadline replaces the client's fixed timeout, which is too short for large uploads.
+func (c *Client) httpClientFor(ctx context.Context) *http.Client {
+	if _, ok := ctx.Deadline(); !ok {
+		return c.httpClient
+	}
+	unbounded := *c.httpClient
+	unbounded.Timeout = 0
+	return &unbounded
+}
+
 // makeRequest makes an HTTP request to the Flaro API
 func (c *Client) makeRequest(method, endpoint string, body interface{}) (*http.Response, error) {
 	return c.makeAuthenticatedRequest(method, endpoint, body, "")
@@ -87,6 +111,21 @@ func (c *Client) makeRequest(method, endpoint string, body interface{}) (*http.R
 
 // makeAuthenticatedRequest makes an HTTP request to the Flaro API with optional authentication
 func (c *Client) makeAuthenticatedRequest(method, endpoint string, body interface{}, accessToken string) (*http.Response, error) {
+	return c.makeRequestWithHeaders(method, endpoint, body, accessToken, nil)
+}
+
+// makeRequestWithHeaders makes an HTTP request to the Flaro API with optional authentication
+// and extra headers (e.g. PostgREST "Prefer")
+func (c *Client) makeRequestWithHeaders(method, endpoint string, body interface{}, accessToken string, headers map[string]string) (*http.Response, error) {
+	req, err := c.newRequest(method, endpoint, body, accessToken, headers)
+	if err != nil {
+		return nil, err
+	}
+	return c.httpClient.Do(req)
+}
+
+// newRequest builds a request with the JSON body and the headers every API call sends
+func (c *Client) newRequest(method, endpoint string, body interface{}, accessToken string, headers map[string]string) (*http.Request, error) {
 	var reqBody io.Reader
 	if body != nil {
 		jsonData, err := json.Marshal(body)
@@ -113,5 +152,9 @@ func (c *Client) makeAuthenticatedRequest(method, endpoint string, body interfac
 		req.Header.Set("authorization", "Bearer "+accessToken)
 	}
 
-	return c.httpClient.Do(req)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	return req, nil
 }
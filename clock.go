@@ -0,0 +1,49 @@
+package flaro
+
+import "time"
+
+// writeTimestampLayout is RFC 3339 with fixed microsecond precision, as Postgres stores it
+const writeTimestampLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+// Clock supplies the current time for timestamps the client writes (created_at, last_seen, ...).
+// Replace it with SetClock to freeze time in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface
+type ClockFunc func() time.Time
+
+// Now implements Clock
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// systemClock is the default Clock backed by time.Now
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SetClock replaces the client's clock. Passing nil restores the system clock.
+func (c *Client) SetClock(clock Clock) {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	c.clock = clock
+}
+
+// now returns the current time from the client's clock
+func (c *Client) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock.Now()
+}
+
+// FormatTimestamp renders t the way every write in this SDK sends timestamps:
+// UTC, RFC 3339 with microseconds, e.g. "2025-09-21T12:57:47.818532Z"
+func FormatTimestamp(t time.Time) string {
+	return t.UTC().Format(writeTimestampLayout)
+}
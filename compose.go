@@ -0,0 +1,414 @@
+package flaro
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptrace"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultUploadConcurrency is how many images ComposePost uploads at once by default
+const DefaultUploadConcurrency = 3
+
+// ComposePost uploads the draft's images concurrently (at most draft.Concurrency at a time),
+// creates the post with their URLs and returns it. If an upload fails or the server rejects
+// the post, the other uploads are cancelled and every image uploaded so far is deleted
+// again. If the post request was sent but no response came back, the post may exist, so
+// the images are kept and the error says so.
+func (c *Client) ComposePost(ctx context.Context, accessToken string, draft PostDraft) (*Post, error) {
+	if draft.CreatorID == "" {
+		return nil, fmt.Errorf("post draft has no creator")
+	}
+	if strings.TrimSpace(draft.Content) == "" && len(draft.Images) == 0 {
+		return nil, fmt.Errorf("post draft has no content or images")
+	}
+
+	uploads, err := c.uploadDraftImages(ctx, accessToken, draft)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaURLs := make([]string, len(uploads))
+	for i, upload := range uploads {
+		mediaURLs[i] = upload.URL
+	}
+	createdAt := c.now()
+	req := CreatePostRequest{
+		CreatorID: draft.CreatorID,
+		Content:   draft.Content,
+		MediaURLs: mediaURLs,
+		CreatedAt: FormatTimestamp(createdAt),
+		Tags:      nonNilStrings(draft.Tags),
+		Score:     0,
+		BoostEnds: nil,
+		Boost:     1,
+		IsPrivate: draft.IsPrivate,
+		Location:  draft.Location,
+		Mentions:  nonNilStrings(draft.Mentions),
+		Comments:  []string{},
+		Likes:     []string{},
+	}
+	post, mayExist, err := c.insertPost(accessToken, req, createdAt)
+	if err != nil {
+		if mayExist {
+			// The post may have been created, so its images must stay
+			return nil, fmt.Errorf("failed to create post, it may still exist: %w", err)
+		}
+		return nil, c.rollbackUploads(accessToken, imageKeys(draft.ImageOptions, uploads), fmt.Errorf("failed to create post: %w", err))
+	}
+	return post, nil
+}
+
+// ComposeReel validates the draft's video, uploads it and the optional cover image, and
+// creates the reel. The container is sniffed from the file header (MP4, MOV or WebM) and
+// the size and, for MP4/MOV, the duration are checked before anything is uploaded. If an
+// upload fails or the server rejects the reel, the uploaded files are deleted again; if the
+// reel request was sent but no response came back, they are kept as for ComposePost.
+//
+// A Video that isn't an io.ReadSeeker is buffered in memory (up to the size limit) so
+// its movie header can be read.
+func (c *Client) ComposeReel(ctx context.Context, accessToken string, draft ReelDraft) (*ComposedReel, error) {
+	if draft.CreatorID == "" {
+		return nil, fmt.Errorf("reel draft has no creator")
+	}
+	if draft.Video == nil {
+		return nil, fmt.Errorf("reel draft has no video")
+	}
+	videoOpts := draft.VideoOptions
+	if videoOpts.MaxSize <= 0 {
+		videoOpts.MaxSize = DefaultMaxVideoSize
+	}
+	maxDuration := draft.MaxDuration
+	if maxDuration <= 0 {
+		maxDuration = DefaultMaxReelDuration
+	}
+
+	video, ok := draft.Video.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(io.LimitReader(draft.Video, videoOpts.MaxSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read video: %w", err)
+		}
+		video = bytes.NewReader(data)
+	}
+	info, err := InspectVideo(video)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size == 0 {
+		return nil, fmt.Errorf("%w: video is empty", ErrInvalidVideo)
+	}
+	if info.Size > videoOpts.MaxSize {
+		return nil, fmt.Errorf("%w of %d bytes", ErrUploadTooLarge, videoOpts.MaxSize)
+	}
+	if info.Duration > maxDuration {
+		return nil, fmt.Errorf("%w: video is %s long, the limit is %s", ErrInvalidVideo, info.Duration.Round(time.Second), maxDuration)
+	}
+
+	var cover []byte
+	coverOpts := draft.CoverOptions
+	if draft.Cover != nil {
+		if coverOpts.MaxSize <= 0 {
+			coverOpts.MaxSize = DefaultMaxImageSize
+		}
+		if cover, err = io.ReadAll(io.LimitReader(draft.Cover, coverOpts.MaxSize+1)); err != nil {
+			return nil, fmt.Errorf("failed to read cover image: %w", err)
+		}
+		if int64(len(cover)) > coverOpts.MaxSize {
+			return nil, fmt.Errorf("cover image: %w of %d bytes", ErrUploadTooLarge, coverOpts.MaxSize)
+		}
+		if coverOpts.ContentType, _, err = detectImageType(cover); err != nil {
+			return nil, fmt.Errorf("%w: cover image: %v", ErrInvalidImage, err)
+		}
+	}
+
+	videoOpts.ContentType = info.ContentType
+	videoOpts.Size = info.Size
+	videoResp, err := c.UploadVideoFrom(ctx, accessToken, video, videoOpts)
+	if err != nil {
+		return nil, err
+	}
+	result := &ComposedReel{Video: videoResp}
+	var keys []string
+	if !videoOpts.Dedup {
+		keys = append(keys, videoResp.Key)
+	}
+
+	if cover != nil {
+		coverOpts.Size = int64(len(cover))
+		coverResp, err := c.UploadImageFrom(ctx, accessToken, bytes.NewReader(cover), coverOpts)
+		if err != nil {
+			return nil, c.rollbackUploads(accessToken, keys, fmt.Errorf("failed to upload cover image: %w", err))
+		}
+		result.Cover = coverResp
+		keys = append(keys, imageKeys(coverOpts, []*ImageUploadResponse{coverResp})...)
+	}
+
+	createdAt := c.now()
+	req := CreateReelRequest{
+		CreatorID: draft.CreatorID,
+		Content:   draft.Content,
+		Video:     videoResp.URL,
+		CreatedAt: FormatTimestamp(createdAt),
+		Tags:      nonNilStrings(draft.Tags),
+		Score:     0,
+		BoostEnds: nil,
+		Boost:     1,
+		IsPrivate: draft.IsPrivate,
+		Location:  draft.Location,
+		Mentions:  nonNilStrings(draft.Mentions),
+		Comments:  []string{},
+		Likes:     []string{},
+	}
+	reel, mayExist, err := c.insertReel(accessToken, req, createdAt)
+	if err != nil {
+		if mayExist {
+			// The reel may have been created, so its video and cover must stay
+			return nil, fmt.Errorf("failed to create reel, it may still exist: %w", err)
+		}
+		return nil, c.rollbackUploads(accessToken, keys, fmt.Errorf("failed to create reel: %w", err))
+	}
+	result.Reel = *reel
+	return result, nil
+}
+
+// uploadDraftImages uploads draft.Images with bounded parallelism, returning the responses
+// in the same order as the images
+func (c *Client) uploadDraftImages(ctx context.Context, accessToken string, draft PostDraft) ([]*ImageUploadResponse, error) {
+	concurrency := draft.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultUploadConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	uploads := make([]*ImageUploadResponse, len(draft.Images))
+	errs := make([]error, len(draft.Images))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, image := range draft.Images {
+		wg.Add(1)
+		go func(i int, image io.Reader) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			uploads[i], errs[i] = c.uploadDraftImage(ctx, accessToken, image, draft)
+			if errs[i] != nil {
+				// Stop the remaining uploads; they would only be deleted again
+				cancel()
+			}
+		}(i, image)
+	}
+	wg.Wait()
+
+	var firstErr error
+	for i, err := range errs {
+		// Uploads cancelled because of another failure aren't the cause worth reporting
+		if err != nil && (firstErr == nil || errors.Is(firstErr, context.Canceled)) {
+			firstErr = fmt.Errorf("failed to upload image %d: %w", i+1, err)
+		}
+	}
+	if firstErr != nil {
+		var done []*ImageUploadResponse
+		for _, upload := range uploads {
+			if upload != nil {
+				done = append(done, upload)
+			}
+		}
+		return nil, c.rollbackUploads(accessToken, imageKeys(draft.ImageOptions, done), firstErr)
+	}
+	return uploads, nil
+}
+
+// uploadDraftImage processes an image if the draft asks for it and uploads it
+func (c *Client) uploadDraftImage(ctx context.Context, accessToken string, image io.Reader, draft PostDraft) (*ImageUploadResponse, error) {
+	opts := draft.ImageOptions
+	if draft.Processing != nil {
+		processing := *draft.Processing
+		processing.ThumbnailSize = 0
+		processed, err := ProcessImage(image, processing)
+		if err != nil {
+			return nil, fmt.Errorf("failed to process image: %w", err)
+		}
+		image = bytes.NewReader(processed.Data)
+		opts.Filename = ""
+		opts.ContentType = processed.ContentType
+		opts.Size = int64(len(processed.Data))
+	}
+	return c.UploadImageFrom(ctx, accessToken, image, opts)
+}
+
+// imageKeys returns the keys of uploads made with opts that may be deleted on rollback.
+// Deduplicated objects may be shared with other posts and are left in place.
+func imageKeys(opts UploadOptions, uploads []*ImageUploadResponse) []string {
+	if opts.Dedup {
+		return nil
+	}
+	keys := make([]string, 0, len(uploads))
+	for _, upload := range uploads {
+		keys = append(keys, upload.Key)
+	}
+	return keys
+}
+
+// rollbackUploads deletes uploaded objects after a failed compose and returns cause, joined
+// with the cleanup error if the objects couldn't be removed
+func (c *Client) rollbackUploads(accessToken string, keys []string, cause error) error {
+	if len(keys) == 0 {
+		return cause
+	}
+	if err := c.removeObjects(accessToken, keys); err != nil {
+		return errors.Join(cause, fmt.Errorf("failed to remove uploaded files: %w", err))
+	}
+	return cause
+}
+
+// removeObjects deletes objects by their upload keys ("bucket/path"), one request per bucket
+func (c *Client) removeObjects(accessToken string, keys []string) error {
+	byBucket := make(map[string][]string)
+	var buckets []string
+	for _, key := range keys {
+		bucket, objectPath, ok := splitObjectKey(key)
+		if !ok {
+			return fmt.Errorf("invalid object key %q", key)
+		}
+		if _, seen := byBucket[bucket]; !seen {
+			buckets = append(buckets, bucket)
+		}
+		byBucket[bucket] = append(byBucket[bucket], objectPath)
+	}
+
+	storage := c.Storage(accessToken)
+	var errs []error
+	for _, bucket := range buckets {
+		if err := storage.Remove(bucket, byBucket[bucket]...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// insertPost creates a post and returns the stored row. A 201 without a readable row
+// still means the post exists, so it is returned with the fields that were sent. The bool
+// reports whether the post may exist despite an error; see insertReturning.
+func (c *Client) insertPost(accessToken string, req CreatePostRequest, createdAt time.Time) (*Post, bool, error) {
+	var posts []Post
+	if mayExist, err := c.insertReturning(accessToken, "/rest/v1/posts?select=*", "create post", req, &posts); err != nil {
+		return nil, mayExist, err
+	}
+	if len(posts) > 0 {
+		return &posts[0], true, nil
+	}
+	return &Post{
+		CreatorID: req.CreatorID,
+		Content:   req.Content,
+		MediaURLs: req.MediaURLs,
+		CreatedAt: NewTimestamp(createdAt),
+		Tags:      req.Tags,
+		Score:     req.Score,
+		Boost:     req.Boost,
+		IsPrivate: req.IsPrivate,
+		Location:  req.Location,
+		Mentions:  req.Mentions,
+		Comments:  req.Comments,
+		Likes:     req.Likes,
+	}, true, nil
+}
+
+// insertReel creates a reel and returns the stored row, or one built from the fields that
+// were sent if the 201 carried no readable row. The bool is as for insertPost.
+func (c *Client) insertReel(accessToken string, req CreateReelRequest, createdAt time.Time) (*Reel, bool, error) {
+	var reels []Reel
+	if mayExist, err := c.insertReturning(accessToken, "/rest/v1/reels?select=*", "create reel", req, &reels); err != nil {
+		return nil, mayExist, err
+	}
+	if len(reels) > 0 {
+		return &reels[0], true, nil
+	}
+	return &Reel{
+		CreatorID: req.CreatorID,
+		Content:   req.Content,
+		Video:     req.Video,
+		CreatedAt: NewTimestamp(createdAt),
+		Tags:      req.Tags,
+		Score:     req.Score,
+		Boost:     req.Boost,
+		IsPrivate: req.IsPrivate,
+		Location:  req.Location,
+		Mentions:  req.Mentions,
+		Comments:  req.Comments,
+		Likes:     req.Likes,
+	}, true, nil
+}
+
+// insertReturning POSTs req to a REST endpoint with "Prefer: return=representation" and
+// decodes the inserted rows into out. Any 2xx status means the insert happened, so an
+// empty, unreadable or partly invalid body leaves out untouched rather than failing;
+// callers then build the row from what they sent.
+//
+// On error, the bool reports whether the row may exist anyway: false when the server
+// rejected the insert or the request never went out, true when it was sent but no
+// response arrived. Callers must not clean up after an insert that may exist.
+func (c *Client) insertReturning(accessToken, endpoint, operation string, req, out interface{}) (bool, error) {
+	headers := map[string]string{"Prefer": "return=representation"}
+	httpReq, err := c.newRequest("POST", endpoint, req, accessToken, headers)
+	if err != nil {
+		return false, fmt.Errorf("failed to make %s request: %w", operation, err)
+	}
+	var wrote atomic.Bool
+	trace := &httptrace.ClientTrace{
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			if info.Err == nil {
+				wrote.Store(true)
+			}
+		},
+	}
+	httpReq = httpReq.WithContext(httptrace.WithClientTrace(httpReq.Context(), trace))
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return wrote.Load(), fmt.Errorf("failed to make %s request: %w", operation, err)
+	}
+	defer resp.Body.Close()
+
+	body, readErr := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr APIError
+		if err := json.Unmarshal(body, &apiErr); err != nil || apiErr.Message == "" {
+			return false, fmt.Errorf("%s failed with status %d: %s", operation, resp.StatusCode, string(body))
+		}
+		return false, &apiErr
+	}
+
+	if readErr == nil && len(bytes.TrimSpace(body)) > 0 {
+		// Decode into a fresh value: a decode that fails part way would leave out with
+		// some fields filled, which callers couldn't tell from the stored row
+		decoded := reflect.New(reflect.TypeOf(out).Elem())
+		if err := json.Unmarshal(body, decoded.Interface()); err == nil {
+			reflect.ValueOf(out).Elem().Set(decoded.Elem())
+		}
+	}
+	return true, nil
+}
+
+// nonNilStrings returns values, or an empty slice so it is sent as [] rather than null
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,111 @@
+package flaro
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// CountMode selects how PostgREST computes a row count
+type CountMode string
+
+const (
+	// CountExact runs a full COUNT(*) on the server (accurate, slowest on big tables)
+	CountExact CountMode = "exact"
+	// CountPlanned uses the Postgres planner statistics (fast, approximate)
+	CountPlanned CountMode = "planned"
+	// CountEstimated uses exact counts for small tables and planned counts above a threshold
+	CountEstimated CountMode = "estimated"
+)
+
+// Count returns the number of rows in table matching filters without downloading them.
+// It issues a HEAD request with "Prefer: count=<mode>" and parses the Content-Range header.
+// filters uses PostgREST syntax, e.g. url.Values{"post_id": {"eq.<id>"}}. An empty mode means CountExact.
+func (c *Client) Count(accessToken, table string, filters url.Values, mode CountMode) (int, error) {
+	if table == "" {
+		return 0, fmt.Errorf("table is required")
+	}
+	if mode == "" {
+		mode = CountExact
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("select", "*")
+	for key, values := range filters {
+		for _, v := range values {
+			queryParams.Add(key, v)
+		}
+	}
+
+	endpoint := "/rest/v1/" + table + "?" + queryParams.Encode()
+	headers := map[string]string{"Prefer": "count=" + string(mode)}
+
+	// Make the request
+	resp, err := c.makeRequestWithHeaders("HEAD", endpoint, nil, accessToken, headers)
+	if err != nil {
+		return 0, fmt.Errorf("failed to make count request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// HEAD responses carry no body, so only the status is available on failure
+	if resp.StatusCode != 200 && resp.StatusCode != 206 {
+		return 0, fmt.Errorf("count %s failed with status %d", table, resp.StatusCode)
+	}
+
+	return parseContentRangeTotal(resp.Header.Get("Content-Range"))
+}
+
+// parseContentRangeTotal extracts the total from a PostgREST Content-Range header ("0-24/3573" or "*/0")
+func parseContentRangeTotal(contentRange string) (int, error) {
+	if contentRange == "" {
+		return 0, fmt.Errorf("missing Content-Range header in count response")
+	}
+	idx := strings.LastIndex(contentRange, "/")
+	if idx < 0 || idx == len(contentRange)-1 {
+		return 0, fmt.Errorf("malformed Content-Range header: %q", contentRange)
+	}
+	total := contentRange[idx+1:]
+	if total == "*" {
+		return 0, fmt.Errorf("server did not report a total in Content-Range: %q", contentRange)
+	}
+	n, err := strconv.Atoi(total)
+	if err != nil {
+		return 0, fmt.Errorf("malformed Content-Range total %q: %w", total, err)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("malformed Content-Range total %q", total)
+	}
+	return n, nil
+}
+
+// CountUserPosts returns the number of posts created by a user
+func (c *Client) CountUserPosts(accessToken, userID string) (int, error) {
+	return c.Count(accessToken, "posts", url.Values{"creator_id": {"eq." + userID}}, CountExact)
+}
+
+// CountPostComments returns the number of comments on a post
+func (c *Client) CountPostComments(accessToken, postID string) (int, error) {
+	return c.Count(accessToken, "comments", url.Values{"post_id": {"eq." + postID}}, CountExact)
+}
+
+// CountReelComments returns the number of comments on a reel
+func (c *Client) CountReelComments(accessToken, reelID string) (int, error) {
+	return c.Count(accessToken, "comments", url.Values{"reel_id": {"eq." + reelID}}, CountExact)
+}
+
+// CountFollowers returns the number of users following a user
+func (c *Client) CountFollowers(accessToken, userID string) (int, error) {
+	return c.Count(accessToken, "follows", url.Values{"following_id": {"eq." + userID}}, CountExact)
+}
+
+// CountFollowing returns the number of users a user follows
+func (c *Client) CountFollowing(accessToken, userID string) (int, error) {
+	return c.Count(accessToken, "follows", url.Values{"follower_id": {"eq." + userID}}, CountExact)
+}
+
+// CountGlobalMessages returns the number of messages in the Global Channel.
+// The messages table grows quickly, so this uses CountEstimated.
+func (c *Client) CountGlobalMessages(accessToken string) (int, error) {
+	return c.Count(accessToken, "messages", nil, CountEstimated)
+}
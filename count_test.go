@@ -0,0 +1,34 @@
+package flaro
+
+import "testing"
+
+func TestParseContentRangeTotal(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    int
+		wantErr bool
+	}{
+		{"0-24/3573", 3573, false},
+		{"*/0", 0, false},
+		{"*/42", 42, false},
+		{"0-0/1", 1, false},
+		{"*/*", 0, true},
+		{"0-24/*", 0, true},
+		{"", 0, true},
+		{"0-24", 0, true},
+		{"0-24/", 0, true},
+		{"*/abc", 0, true},
+		{"*/-5", 0, true},
+		{"*/99999999999999999999", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseContentRangeTotal(tt.header)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: err = %v, wantErr %v", tt.header, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: got %d, want %d", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package flaro
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Resource embeddings for author profiles. The "author" alias keeps the JSON key stable
+// regardless of which foreign key is used.
+const (
+	postAuthorEmbed    = "author:users!posts_creator_id_fkey(*)"
+	reelAuthorEmbed    = "author:users!reels_creator_id_fkey(*)"
+	commentAuthorEmbed = "author:users!comments_user_id_fkey(*)"
+	globalSenderEmbed  = "sender:users!messages_sender_id_fkey(*)"
+)
+
+// GetPostsWithAuthors retrieves posts like GetPosts with each creator's profile embedded.
+// If the server cannot embed users, authors are resolved with a batched profile lookup instead.
+func (c *Client) GetPostsWithAuthors(accessToken string, params *PostsQueryParams) ([]PostWithAuthor, error) {
+	if params == nil {
+		params = &PostsQueryParams{
+			Select: "*",
+			Order:  "created_at.desc.nullslast",
+			Offset: 0,
+			Limit:  20,
+		}
+	}
+	sel := params.Select
+	if sel == "" {
+		sel = "*"
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("select", sel+","+postAuthorEmbed)
+	queryParams.Set("order", params.Order)
+	queryParams.Set("offset", strconv.Itoa(params.Offset))
+	queryParams.Set("limit", strconv.Itoa(params.Limit))
+
+	var posts []PostWithAuthor
+	err := c.fetchJSON(accessToken, "/rest/v1/posts?"+queryParams.Encode(), "get posts with authors", &posts)
+	if err == nil {
+		for i := range posts {
+			c.cacheProfiles(posts[i].Author)
+		}
+		return posts, nil
+	}
+	if !isEmbeddingUnavailable(err) {
+		return nil, err
+	}
+
+	// Fallback: plain query plus batched author lookup
+	plain, err := c.GetPosts(accessToken, params)
+	if err != nil {
+		return nil, err
+	}
+	return c.attachPostAuthors(accessToken, plain)
+}
+
+// GetReelsWithAuthors retrieves all reels like GetReels with each creator's profile embedded.
+// If the server cannot embed users, authors are resolved with a batched profile lookup instead.
+func (c *Client) GetReelsWithAuthors(accessToken string) ([]ReelWithAuthor, error) {
+	queryParams := url.Values{}
+	queryParams.Set("select", "*,"+reelAuthorEmbed)
+	queryParams.Set("order", "created_at.desc.nullslast")
+
+	var reels []ReelWithAuthor
+	err := c.fetchJSON(accessToken, "/rest/v1/reels?"+queryParams.Encode(), "get reels with authors", &reels)
+	if err == nil {
+		for i := range reels {
+			c.cacheProfiles(reels[i].Author)
+		}
+		return reels, nil
+	}
+	if !isEmbeddingUnavailable(err) {
+		return nil, err
+	}
+
+	plain, err := c.GetReels(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	return c.attachReelAuthors(accessToken, plain)
+}
+
+// GetCommentsWithAuthors retrieves comments for a post like GetComments with each author's profile embedded
+func (c *Client) GetCommentsWithAuthors(accessToken, postID string) ([]CommentWithAuthor, error) {
+	queryParams := url.Values{}
+	queryParams.Set("select", "*,"+commentAuthorEmbed)
+	queryParams.Set("post_id", "eq."+postID)
+	queryParams.Set("order", "created_at.asc.nullslast")
+
+	var comments []CommentWithAuthor
+	err := c.fetchJSON(accessToken, "/rest/v1/comments?"+queryParams.Encode(), "get comments with authors", &comments)
+	if err == nil {
+		for i := range comments {
+			c.cacheProfiles(comments[i].Author)
+		}
+		return comments, nil
+	}
+	if !isEmbeddingUnavailable(err) {
+		return nil, err
+	}
+
+	plain, err := c.GetComments(accessToken, postID)
+	if err != nil {
+		return nil, err
+	}
+	return c.attachCommentAuthors(accessToken, plain)
+}
+
+// GetReelCommentsWithAuthors retrieves comments for a reel like GetReelComments with each author's profile embedded
+func (c *Client) GetReelCommentsWithAuthors(accessToken, reelID string) ([]CommentWithAuthor, error) {
+	queryParams := url.Values{}
+	queryParams.Set("select", "*,"+commentAuthorEmbed)
+	queryParams.Set("reel_id", "eq."+reelID)
+	queryParams.Set("order", "created_at.asc.nullslast")
+
+	var comments []CommentWithAuthor
+	err := c.fetchJSON(accessToken, "/rest/v1/comments?"+queryParams.Encode(), "get reel comments with authors", &comments)
+	if err == nil {
+		for i := range comments {
+			c.cacheProfiles(comments[i].Author)
+		}
+		return comments, nil
+	}
+	if !isEmbeddingUnavailable(err) {
+		return nil, err
+	}
+
+	plain, err := c.GetReelComments(accessToken, reelID)
+	if err != nil {
+		return nil, err
+	}
+	return c.attachCommentAuthors(accessToken, plain)
+}
+
+// GetGlobalMessagesWithSenders retrieves Global Channel messages like GetGlobalMessagesBefore
+// with each sender's profile embedded. If the server cannot embed users, senders are
+// resolved with a batched profile lookup instead.
+func (c *Client) GetGlobalMessagesWithSenders(accessToken string, before, limit int) ([]GlobalMessageWithSender, error) {
+	if limit <= 0 {
+		limit = DefaultGlobalHistoryLimit
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("select", "*,"+globalSenderEmbed)
+	if before > 0 {
+		queryParams.Set("id", "lt."+strconv.Itoa(before))
+	}
+	queryParams.Set("order", "id.desc")
+	queryParams.Set("limit", strconv.Itoa(limit))
+
+	messages := []GlobalMessageWithSender{}
+	err := c.fetchJSON(accessToken, "/rest/v1/messages?"+queryParams.Encode(), "get global messages with senders", &messages)
+	if err == nil {
+		for i := range messages {
+			c.cacheProfiles(messages[i].Sender)
+		}
+		// Oldest first, like GetGlobalMessagesBefore
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+		return messages, nil
+	}
+	if !isEmbeddingUnavailable(err) {
+		return nil, err
+	}
+
+	plain, err := c.GetGlobalMessagesBefore(accessToken, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	return c.AttachGlobalMessageSenders(accessToken, plain)
+}
+
+// AttachGlobalMessageSenders pairs messages, e.g. from a GlobalChannelSync, with their
+// senders' profiles using a batched, cached lookup
+func (c *Client) AttachGlobalMessageSenders(accessToken string, messages []GlobalMessage) ([]GlobalMessageWithSender, error) {
+	ids := make([]string, 0, len(messages))
+	for _, m := range messages {
+		ids = append(ids, m.SenderID)
+	}
+	profiles, err := c.lookupProfiles(accessToken, ids)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]GlobalMessageWithSender, 0, len(messages))
+	for _, m := range messages {
+		out = append(out, GlobalMessageWithSender{GlobalMessage: m, Sender: profiles[m.SenderID]})
+	}
+	return out, nil
+}
+
+// attachPostAuthors pairs posts with their creators' profiles using a batched lookup
+func (c *Client) attachPostAuthors(accessToken string, posts []Post) ([]PostWithAuthor, error) {
+	ids := make([]string, 0, len(posts))
+	for _, p := range posts {
+		ids = append(ids, p.CreatorID)
+	}
+	profiles, err := c.lookupProfiles(accessToken, ids)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]PostWithAuthor, 0, len(posts))
+	for _, p := range posts {
+		out = append(out, PostWithAuthor{Post: p, Author: profiles[p.CreatorID]})
+	}
+	return out, nil
+}
+
+// attachReelAuthors pairs reels with their creators' profiles using a batched lookup
+func (c *Client) attachReelAuthors(accessToken string, reels []Reel) ([]ReelWithAuthor, error) {
+	ids := make([]string, 0, len(reels))
+	for _, r := range reels {
+		ids = append(ids, r.CreatorID)
+	}
+	profiles, err := c.lookupProfiles(accessToken, ids)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ReelWithAuthor, 0, len(reels))
+	for _, r := range reels {
+		out = append(out, ReelWithAuthor{Reel: r, Author: profiles[r.CreatorID]})
+	}
+	return out, nil
+}
+
+// attachCommentAuthors pairs comments with their authors' profiles using a batched lookup
+func (c *Client) attachCommentAuthors(accessToken string, comments []Comment) ([]CommentWithAuthor, error) {
+	ids := make([]string, 0, len(comments))
+	for _, cm := range comments {
+		ids = append(ids, cm.UserID)
+	}
+	profiles, err := c.lookupProfiles(accessToken, ids)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]CommentWithAuthor, 0, len(comments))
+	for _, cm := range comments {
+		out = append(out, CommentWithAuthor{Comment: cm, Author: profiles[cm.UserID]})
+	}
+	return out, nil
+}
+
+// lookupProfiles resolves ids to profiles via GetUsers, keyed by user ID.
+// Missing users are simply absent from the returned map.
+func (c *Client) lookupProfiles(accessToken string, ids []string) (map[string]*UserProfile, error) {
+	users, err := c.GetUsers(accessToken, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up authors: %w", err)
+	}
+	profiles := make(map[string]*UserProfile, len(users))
+	for i := range users {
+		profiles[users[i].UserID] = &users[i]
+	}
+	return profiles, nil
+}
+
+// isEmbeddingUnavailable reports whether err is PostgREST rejecting a resource embedding,
+// e.g. because the foreign key does not exist (PGRST200) or is ambiguous (PGRST201)
+func isEmbeddingUnavailable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Code == "PGRST200" || apiErr.Code == "PGRST201"
+}
@@ -227,10 +227,12 @@ func main() {
 
 		// Example: Get reels
 		fmt.Println("\n=== Get Reels Example ===")
-		reels, err := client.GetReels(authResp.AccessToken)
+		var reels []flaro.Reel
+		reelsPage, err := client.GetReelsPage(authResp.AccessToken, &flaro.ReelsPageParams{Limit: 5})
 		if err != nil {
 			log.Printf("Get reels failed: %v", err)
 		} else {
+			reels = reelsPage.Reels
 			fmt.Printf("Found %d reels\n", len(reels))
 			if len(reels) > 0 {
 				fmt.Printf("Latest reel: %s by %s\n", reels[0].Content, reels[0].CreatorID)
@@ -240,11 +242,11 @@ func main() {
 		// Example: Get specific reel by ID (if we have reels)
 		if len(reels) > 0 {
 			fmt.Println("\n=== Get Reel By ID Example ===")
-			specificReels, err := client.GetReelByID(authResp.AccessToken, reels[0].ID)
+			specificReel, err := client.GetReel(authResp.AccessToken, reels[0].ID)
 			if err != nil {
 				log.Printf("Get reel by ID failed: %v", err)
 			} else {
-				fmt.Printf("Found reel: %s\n", specificReels[0].Content)
+				fmt.Printf("Found reel: %s\n", specificReel.Content)
 			}
 
 			// Example: Get reel comments
@@ -399,8 +401,7 @@ func main() {
 		//
 		//     // Example: Create reel with uploaded video
 		//     fmt.Println("\n=== Create Reel Example ===")
-		//     videoURL := fmt.Sprintf("https://sb.flaroapp.pl/storage/v1/object/public/%s", videoResp.Key)
-		//     err = client.CreateReel(authResp.AccessToken, authResp.User.ID, "Test reel from Go SDK!", videoURL)
+		//     err = client.CreateReel(authResp.AccessToken, authResp.User.ID, "Test reel from Go SDK!", videoResp.URL)
 		//     if err != nil {
 		//         log.Printf("Create reel failed: %v", err)
 		//     } else {
@@ -419,10 +420,11 @@ func main() {
 
 		// Example: Global chat - send message (commented out to avoid spam)
 		// fmt.Println("\n=== Global Chat (Send) Example ===")
-		// if err := client.SendGlobalMessage(authResp.AccessToken, authResp.User.ID, "hej from Go SDK"); err != nil {
+		// sendOpts := &flaro.SendGlobalMessageOptions{IdempotencyKey: "example-hej-1"}
+		// if sent, err := client.SendGlobalMessage(authResp.AccessToken, authResp.User.ID, "hej from Go SDK", sendOpts); err != nil {
 		// 	log.Printf("Send global message failed: %v", err)
 		// } else {
-		// 	fmt.Println("Sent message to Global Channel")
+		// 	fmt.Printf("Sent message %d to Global Channel\n", sent.ID)
 		// }
 
 		// Realtime (experimental) example - build with `-tags realtime`
@@ -0,0 +1,166 @@
+package flaro
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// Follow makes followerID follow followingID. Following a user that is already followed is a no-op.
+func (c *Client) Follow(accessToken, followerID, followingID string) error {
+	if followerID == "" || followingID == "" {
+		return fmt.Errorf("follower ID and following ID are required")
+	}
+	if followerID == followingID {
+		return fmt.Errorf("cannot follow yourself")
+	}
+
+	req := FollowRequest{
+		FollowerID:  followerID,
+		FollowingID: followingID,
+	}
+
+	endpoint := "/rest/v1/follows"
+	resp, err := c.makeAuthenticatedRequest("POST", endpoint, req, accessToken)
+	if err != nil {
+		return fmt.Errorf("failed to follow user: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// 409 means the relationship already exists (unique follower/following pair)
+	if resp.StatusCode == 409 {
+		return nil
+	}
+
+	// Check for errors
+	if resp.StatusCode != 201 {
+		var apiErr APIError
+		if err := json.Unmarshal(body, &apiErr); err != nil {
+			return fmt.Errorf("follow user failed with status %d: %s", resp.StatusCode, string(body))
+		}
+		return fmt.Errorf("follow user failed: %s", apiErr.Message)
+	}
+
+	// The API returns no response body for successful follow (201 status)
+	return nil
+}
+
+// Unfollow removes the follow relationship from followerID to followingID
+func (c *Client) Unfollow(accessToken, followerID, followingID string) error {
+	if followerID == "" || followingID == "" {
+		return fmt.Errorf("follower ID and following ID are required")
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("follower_id", "eq."+followerID)
+	queryParams.Set("following_id", "eq."+followingID)
+
+	endpoint := "/rest/v1/follows?" + queryParams.Encode()
+	resp, err := c.makeAuthenticatedRequest("DELETE", endpoint, nil, accessToken)
+	if err != nil {
+		return fmt.Errorf("failed to unfollow user: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Check for errors
+	if resp.StatusCode != 204 {
+		var apiErr APIError
+		if err := json.Unmarshal(body, &apiErr); err != nil {
+			return fmt.Errorf("unfollow user failed with status %d: %s", resp.StatusCode, string(body))
+		}
+		return fmt.Errorf("unfollow user failed: %s", apiErr.Message)
+	}
+
+	// The API returns no response body for successful deletion (204 status)
+	return nil
+}
+
+// GetFollowers retrieves users that follow a specific user. Each Follow has FollowerID set
+// and Users holds the follower's profile.
+func (c *Client) GetFollowers(accessToken, userID string) ([]Follow, error) {
+	// Build query parameters
+	queryParams := url.Values{}
+	queryParams.Set("select", "follower_id,users!follows_follower_id_fkey(*)")
+	queryParams.Set("following_id", "eq."+userID)
+
+	endpoint := "/rest/v1/follows?" + queryParams.Encode()
+
+	// Make the request
+	resp, err := c.makeAuthenticatedRequest("GET", endpoint, nil, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make get followers request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Check for errors
+	if resp.StatusCode != 200 {
+		var apiErr APIError
+		if err := json.Unmarshal(body, &apiErr); err != nil {
+			return nil, fmt.Errorf("get followers failed with status %d: %s", resp.StatusCode, string(body))
+		}
+		return nil, &apiErr
+	}
+
+	// Parse successful response
+	var follows []Follow
+	if err := json.Unmarshal(body, &follows); err != nil {
+		return nil, fmt.Errorf("failed to parse followers response: %w", err)
+	}
+
+	return follows, nil
+}
+
+// IsFollowing reports whether followerID follows followingID
+func (c *Client) IsFollowing(accessToken, followerID, followingID string) (bool, error) {
+	filters := url.Values{}
+	filters.Set("follower_id", "eq."+followerID)
+	filters.Set("following_id", "eq."+followingID)
+
+	count, err := c.Count(accessToken, "follows", filters, CountExact)
+	if err != nil {
+		return false, fmt.Errorf("failed to check follow status: %w", err)
+	}
+	return count > 0, nil
+}
+
+// GetMutuals retrieves the users that both userA and userB follow, in userA's following order
+func (c *Client) GetMutuals(accessToken, userA, userB string) ([]UserProfile, error) {
+	followingA, err := c.GetFollowing(accessToken, userA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get following for %s: %w", userA, err)
+	}
+	followingB, err := c.GetFollowing(accessToken, userB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get following for %s: %w", userB, err)
+	}
+
+	followedByB := make(map[string]bool, len(followingB))
+	for _, f := range followingB {
+		followedByB[f.FollowingID] = true
+	}
+
+	mutuals := make([]UserProfile, 0)
+	for _, f := range followingA {
+		if followedByB[f.FollowingID] {
+			mutuals = append(mutuals, f.Users)
+		}
+	}
+	return mutuals, nil
+}
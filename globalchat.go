@@ -0,0 +1,330 @@
+package flaro
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultGlobalHistoryLimit is how many messages GetGlobalMessagesBefore and a
+	// GlobalChannelSync load at a time when no limit is given
+	DefaultGlobalHistoryLimit = 50
+	// DefaultGlobalBufferSize is how many messages a GlobalChannelSync keeps by default
+	DefaultGlobalBufferSize = 500
+	// globalSincePageSize is how many new messages GetGlobalMessagesSince requests at a time
+	globalSincePageSize = 1000
+	// idempotencyKeyTTL is how long a Client remembers an idempotent send
+	idempotencyKeyTTL = 24 * time.Hour
+)
+
+// ValidateGlobalMessage checks content against the rules SendGlobalMessage enforces. The API
+// documents no length limit, so maxLength (in characters) is only checked when positive.
+// Errors wrap ErrInvalidMessage.
+func ValidateGlobalMessage(content string, maxLength int) error {
+	if strings.TrimSpace(content) == "" {
+		return fmt.Errorf("%w: message cannot be empty", ErrInvalidMessage)
+	}
+	if !utf8.ValidString(content) {
+		return fmt.Errorf("%w: message is not valid UTF-8", ErrInvalidMessage)
+	}
+	if maxLength > 0 && utf8.RuneCountInString(content) > maxLength {
+		return fmt.Errorf("%w: message must be at most %d characters", ErrInvalidMessage, maxLength)
+	}
+	return nil
+}
+
+// GetGlobalMessagesSince retrieves the Global Channel messages with an ID greater than
+// lastID, oldest first. lastID 0 returns the whole channel.
+func (c *Client) GetGlobalMessagesSince(accessToken string, lastID int) ([]GlobalMessage, error) {
+	messages := []GlobalMessage{}
+	for {
+		queryParams := url.Values{}
+		queryParams.Set("select", "*")
+		queryParams.Set("id", "gt."+strconv.Itoa(lastID))
+		queryParams.Set("order", "id.asc")
+		queryParams.Set("limit", strconv.Itoa(globalSincePageSize))
+
+		var page []GlobalMessage
+		endpoint := "/rest/v1/messages?" + queryParams.Encode()
+		if err := c.fetchJSON(accessToken, endpoint, "get global messages", &page); err != nil {
+			return nil, err
+		}
+		messages = append(messages, page...)
+		if len(page) < globalSincePageSize {
+			return messages, nil
+		}
+		lastID = page[len(page)-1].ID
+	}
+}
+
+// GetGlobalMessagesBefore retrieves up to limit Global Channel messages with an ID less than
+// id, oldest first, for scrolling back through history. id 0 returns the latest messages.
+// A limit of 0 or less means DefaultGlobalHistoryLimit.
+func (c *Client) GetGlobalMessagesBefore(accessToken string, id, limit int) ([]GlobalMessage, error) {
+	if limit <= 0 {
+		limit = DefaultGlobalHistoryLimit
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("select", "*")
+	if id > 0 {
+		queryParams.Set("id", "lt."+strconv.Itoa(id))
+	}
+	queryParams.Set("order", "id.desc")
+	queryParams.Set("limit", strconv.Itoa(limit))
+
+	messages := []GlobalMessage{}
+	endpoint := "/rest/v1/messages?" + queryParams.Encode()
+	if err := c.fetchJSON(accessToken, endpoint, "get global messages", &messages); err != nil {
+		return nil, err
+	}
+	// Fetched newest first so the limit keeps the closest messages; return them in reading order
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, nil
+}
+
+// findGlobalMessage looks up the message senderID sent with content at exactly createdAt.
+// It returns nil if there is none.
+func (c *Client) findGlobalMessage(accessToken, senderID, content string, createdAt time.Time) (*GlobalMessage, error) {
+	queryParams := url.Values{}
+	queryParams.Set("select", "*")
+	queryParams.Set("sender_id", "eq."+senderID)
+	queryParams.Set("created_at", "eq."+FormatTimestamp(createdAt))
+	queryParams.Set("content", "eq."+content)
+	queryParams.Set("limit", "1")
+
+	var messages []GlobalMessage
+	endpoint := "/rest/v1/messages?" + queryParams.Encode()
+	if err := c.fetchJSON(accessToken, endpoint, "get global message", &messages); err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	return &messages[0], nil
+}
+
+// idempotencyKeys remembers the created_at each idempotent send used first. The messages
+// table has no key column, so a retry recognises its earlier row by sender, content and
+// that exact timestamp. Keys live in memory only, so they don't outlast the Client.
+type idempotencyKeys struct {
+	mu       sync.Mutex
+	times    map[string]time.Time
+	inFlight map[string]chan struct{}
+}
+
+// begin waits for any other send holding key to finish, then claims key and returns the
+// timestamp to send with: now for a new key, or the one from the first attempt along with
+// true for a retry. Call done once the send has finished. Expired keys are forgotten.
+func (k *idempotencyKeys) begin(key string, now time.Time) (sentAt time.Time, retry bool, done func()) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for {
+		busy, ok := k.inFlight[key]
+		if !ok {
+			break
+		}
+		k.mu.Unlock()
+		<-busy
+		k.mu.Lock()
+	}
+	if k.times == nil {
+		k.times = make(map[string]time.Time)
+		k.inFlight = make(map[string]chan struct{})
+	}
+	for existing, t := range k.times {
+		if now.Sub(t) > idempotencyKeyTTL {
+			delete(k.times, existing)
+		}
+	}
+
+	sentAt, retry = k.times[key]
+	if !retry {
+		// Stored timestamps have microsecond precision; keep the same so the lookup matches
+		sentAt = now.Truncate(time.Microsecond)
+		k.times[key] = sentAt
+	}
+	finished := make(chan struct{})
+	k.inFlight[key] = finished
+	return sentAt, retry, func() {
+		k.mu.Lock()
+		delete(k.inFlight, key)
+		k.mu.Unlock()
+		close(finished)
+	}
+}
+
+// GlobalChannelSync keeps a local copy of the latest Global Channel messages, ordered by ID
+// and without duplicates. New messages come from Poll (id=gt. the newest polled ID) or from
+// realtime frames passed to HandleRealtime; both can be used together.
+type GlobalChannelSync struct {
+	client      *Client
+	mu          sync.Mutex
+	accessToken string
+	bufferSize  int
+	messages    []GlobalMessage
+	known       map[int]bool
+	polledID    int // Newest ID seen by Poll; realtime messages may arrive out of order
+}
+
+// GlobalChannelSync creates a sync helper that keeps at most bufferSize messages (0 or less
+// means DefaultGlobalBufferSize). The buffer starts empty; call Poll or LoadOlder to fill it.
+func (c *Client) GlobalChannelSync(accessToken string, bufferSize int) *GlobalChannelSync {
+	if bufferSize <= 0 {
+		bufferSize = DefaultGlobalBufferSize
+	}
+	return &GlobalChannelSync{client: c, accessToken: accessToken, bufferSize: bufferSize, known: make(map[int]bool)}
+}
+
+// SetAccessToken replaces the token used by later requests, e.g. after RefreshToken
+func (s *GlobalChannelSync) SetAccessToken(accessToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accessToken = accessToken
+}
+
+// Messages returns a copy of the buffered messages, oldest first
+func (s *GlobalChannelSync) Messages() []GlobalMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]GlobalMessage(nil), s.messages...)
+}
+
+// Poll fetches the messages newer than the last poll and returns those that weren't
+// buffered yet, oldest first. The first poll loads the latest bufferSize messages instead
+// of the whole channel.
+func (s *GlobalChannelSync) Poll() ([]GlobalMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var fetched []GlobalMessage
+	var err error
+	if s.polledID == 0 {
+		fetched, err = s.client.GetGlobalMessagesBefore(s.accessToken, 0, s.bufferSize)
+	} else {
+		fetched, err = s.client.GetGlobalMessagesSince(s.accessToken, s.polledID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range fetched {
+		if msg.ID > s.polledID {
+			s.polledID = msg.ID
+		}
+	}
+	added := s.add(fetched)
+	s.trim()
+	return added, nil
+}
+
+// LoadOlder fetches up to limit messages older than the oldest buffered one and returns
+// them, oldest first. Scrollback is kept even if the buffer grows past its size; newer
+// polls trim it again.
+func (s *GlobalChannelSync) LoadOlder(limit int) ([]GlobalMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := 0
+	if len(s.messages) > 0 {
+		before = s.messages[0].ID
+	}
+	fetched, err := s.client.GetGlobalMessagesBefore(s.accessToken, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.add(fetched), nil
+}
+
+// HandleRealtime adds the message carried by a realtime INSERT on the messages table, e.g.
+// from a RealtimeClient subscribed with SubscribeGlobalMessages, and returns it if it is new.
+// Other frames return nothing.
+func (s *GlobalChannelSync) HandleRealtime(env *RealtimeEnvelope) ([]GlobalMessage, error) {
+	var payload struct {
+		Data struct {
+			Table  string          `json:"table"`
+			Type   string          `json:"type"`
+			Record json.RawMessage `json:"record"`
+		} `json:"data"`
+	}
+	if err := env.UnmarshalPayload(&payload); err != nil {
+		return nil, err
+	}
+	if payload.Data.Table != "messages" || payload.Data.Type != "INSERT" || len(payload.Data.Record) == 0 {
+		return nil, nil
+	}
+	var msg GlobalMessage
+	if err := json.Unmarshal(payload.Data.Record, &msg); err != nil {
+		return nil, fmt.Errorf("failed to parse realtime message: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	added := s.add([]GlobalMessage{msg})
+	s.trim()
+	return added, nil
+}
+
+// Run polls every interval until ctx is done and passes new messages to onMessages.
+// It returns ctx.Err() when stopped, or the first polling error.
+func (s *GlobalChannelSync) Run(ctx context.Context, interval time.Duration, onMessages func([]GlobalMessage)) error {
+	if interval <= 0 {
+		return fmt.Errorf("poll interval must be positive")
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		added, err := s.Poll()
+		if err != nil {
+			return err
+		}
+		if len(added) > 0 && onMessages != nil {
+			onMessages(added)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// add merges messages into the buffer, skipping known IDs, and returns the new ones in ID order
+func (s *GlobalChannelSync) add(messages []GlobalMessage) []GlobalMessage {
+	var added []GlobalMessage
+	for _, msg := range messages {
+		if s.known[msg.ID] {
+			continue
+		}
+		s.known[msg.ID] = true
+		added = append(added, msg)
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].ID < added[j].ID })
+	s.messages = append(s.messages, added...)
+	sort.SliceStable(s.messages, func(i, j int) bool { return s.messages[i].ID < s.messages[j].ID })
+	return added
+}
+
+// trim drops the oldest messages beyond the buffer size
+func (s *GlobalChannelSync) trim() {
+	excess := len(s.messages) - s.bufferSize
+	if excess <= 0 {
+		return
+	}
+	for _, msg := range s.messages[:excess] {
+		delete(s.known, msg.ID)
+	}
+	s.messages = append([]GlobalMessage(nil), s.messages[excess:]...)
+}
@@ -0,0 +1,360 @@
+package flaro
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // registers the GIF decoder for image.Decode
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// Defaults for ImageProcessingOptions
+const (
+	DefaultMaxImageDimension = 2048
+	DefaultImageQuality      = 85
+	DefaultMaxImagePixels    = 50_000_000
+	DefaultMaxImageInputSize = 50 << 20 // 50 MiB
+)
+
+// ImageProcessingOptions configures ProcessImage. Zero values pick the defaults.
+type ImageProcessingOptions struct {
+	MaxDimension  int   // Longest side after resizing (default DefaultMaxImageDimension); images are never enlarged
+	Quality       int   // JPEG quality, 1-100 (default DefaultImageQuality)
+	ThumbnailSize int   // Longest side of the thumbnail; 0 skips the thumbnail
+	MaxPixels     int   // Images with more pixels are rejected before decoding (default DefaultMaxImagePixels)
+	MaxInputSize  int64 // Maximum size of the original file in bytes (default DefaultMaxImageInputSize)
+}
+
+// ProcessedImage is the result of ProcessImage
+type ProcessedImage struct {
+	Data                 []byte // Encoded image, without metadata
+	ContentType          string // image/jpeg, image/png or image/gif
+	Width                int
+	Height               int
+	Thumbnail            []byte // Encoded thumbnail, nil unless ThumbnailSize was set
+	ThumbnailContentType string
+}
+
+// ProcessImage prepares a photo for upload. It checks the file's magic bytes, rotates JPEGs
+// according to their EXIF orientation, shrinks the image to opts.MaxDimension and re-encodes
+// it, which drops EXIF (including GPS) and other metadata. JPEGs are re-encoded at
+// opts.Quality and PNGs losslessly. GIFs carry no EXIF and may be animated, so they are
+// kept as they are unless they need shrinking, in which case the first frame is used.
+// WebP can't be decoded by the standard library and is rejected.
+func ProcessImage(r io.Reader, opts ImageProcessingOptions) (*ProcessedImage, error) {
+	if opts.MaxDimension <= 0 {
+		opts.MaxDimension = DefaultMaxImageDimension
+	}
+	if opts.Quality <= 0 || opts.Quality > 100 {
+		opts.Quality = DefaultImageQuality
+	}
+	if opts.MaxPixels <= 0 {
+		opts.MaxPixels = DefaultMaxImagePixels
+	}
+	if opts.MaxInputSize <= 0 {
+		opts.MaxInputSize = DefaultMaxImageInputSize
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, opts.MaxInputSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if int64(len(data)) > opts.MaxInputSize {
+		return nil, fmt.Errorf("%w of %d bytes", ErrUploadTooLarge, opts.MaxInputSize)
+	}
+	contentType, _, err := detectImageType(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if contentType == "image/webp" {
+		return nil, fmt.Errorf("%w: WebP images can't be processed", ErrInvalidImage)
+	}
+
+	// Check the dimensions before decoding so a tiny file can't claim gigapixels
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > opts.MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrInvalidImage, config.Width, config.Height, opts.MaxPixels)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	img := toRGBA(src)
+	if contentType == "image/jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+
+	w, h := fitWithin(img.Rect.Dx(), img.Rect.Dy(), opts.MaxDimension)
+	if w != img.Rect.Dx() || h != img.Rect.Dy() {
+		img = downscale(img, w, h)
+	}
+
+	result := &ProcessedImage{Width: w, Height: h}
+	switch {
+	case contentType == "image/gif" && w == config.Width && h == config.Height:
+		result.Data, result.ContentType = data, contentType
+	case contentType == "image/jpeg":
+		if result.Data, err = encodeJPEG(img, opts.Quality); err != nil {
+			return nil, err
+		}
+		result.ContentType = contentType
+	default:
+		if result.Data, err = encodePNG(img); err != nil {
+			return nil, err
+		}
+		result.ContentType = "image/png"
+	}
+
+	if opts.ThumbnailSize > 0 {
+		tw, th := fitWithin(w, h, opts.ThumbnailSize)
+		thumb := img
+		if tw != w || th != h {
+			thumb = downscale(img, tw, th)
+		}
+		// Thumbnails of PNGs and GIFs stay PNG to keep transparency
+		if contentType == "image/jpeg" {
+			result.Thumbnail, err = encodeJPEG(thumb, opts.Quality)
+			result.ThumbnailContentType = "image/jpeg"
+		} else {
+			result.Thumbnail, err = encodePNG(thumb)
+			result.ThumbnailContentType = "image/png"
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// UploadProcessedImage runs ProcessImage on r and uploads the result, and the thumbnail if
+// one was requested, to the post-images bucket with UploadImageFrom. If the thumbnail upload
+// fails the uploaded image is deleted again.
+func (c *Client) UploadProcessedImage(ctx context.Context, accessToken string, r io.Reader, processing ImageProcessingOptions, opts UploadOptions) (*ProcessedImageUploadResponse, error) {
+	processed, err := ProcessImage(r, processing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process image: %w", err)
+	}
+
+	// The original name's extension may no longer match the re-encoded data
+	opts.Filename = ""
+	opts.ContentType = processed.ContentType
+	opts.Size = int64(len(processed.Data))
+	imageResp, err := c.UploadImageFrom(ctx, accessToken, bytes.NewReader(processed.Data), opts)
+	if err != nil {
+		return nil, err
+	}
+	result := &ProcessedImageUploadResponse{Image: imageResp, Width: processed.Width, Height: processed.Height}
+	if processed.Thumbnail == nil {
+		return result, nil
+	}
+
+	opts.ContentType = processed.ThumbnailContentType
+	opts.Size = int64(len(processed.Thumbnail))
+	opts.Progress = nil
+	thumbResp, err := c.UploadImageFrom(ctx, accessToken, bytes.NewReader(processed.Thumbnail), opts)
+	if err != nil {
+		err = fmt.Errorf("failed to upload thumbnail: %w", err)
+		// Deduplicated images may be shared with earlier posts, so only remove fresh uploads
+		if !opts.Dedup {
+			bucket, objectPath, _ := splitObjectKey(imageResp.Key)
+			if cleanupErr := c.deleteObject(accessToken, bucket, objectPath); cleanupErr != nil {
+				return nil, errors.Join(err, fmt.Errorf("failed to remove uploaded image: %w", cleanupErr))
+			}
+		}
+		return nil, err
+	}
+	result.Thumbnail = thumbResp
+	return result, nil
+}
+
+// toRGBA converts img to an *image.RGBA whose bounds start at the origin
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Rect, img, b.Min, draw.Src)
+	return rgba
+}
+
+// fitWithin scales w x h down to fit in a max x max square, keeping the aspect ratio
+func fitWithin(w, h, max int) (int, int) {
+	if w <= max && h <= max {
+		return w, h
+	}
+	if w >= h {
+		nh := (h*max + w/2) / w
+		if nh < 1 {
+			nh = 1
+		}
+		return max, nh
+	}
+	nw := (w*max + h/2) / h
+	if nw < 1 {
+		nw = 1
+	}
+	return nw, max
+}
+
+// downscale shrinks src to w x h by averaging the source pixels covered by each destination
+// pixel (a box filter), which avoids the aliasing of nearest-neighbour sampling
+func downscale(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for dy := 0; dy < h; dy++ {
+		y0, y1 := dy*sh/h, (dy+1)*sh/h
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for dx := 0; dx < w; dx++ {
+			x0, x1 := dx*sw/w, (dx+1)*sw/w
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var sum [4]uint64
+			for y := y0; y < y1; y++ {
+				off := src.PixOffset(x0, y)
+				for x := x0; x < x1; x++ {
+					sum[0] += uint64(src.Pix[off])
+					sum[1] += uint64(src.Pix[off+1])
+					sum[2] += uint64(src.Pix[off+2])
+					sum[3] += uint64(src.Pix[off+3])
+					off += 4
+				}
+			}
+			n := uint64((x1 - x0) * (y1 - y0))
+			d := dst.PixOffset(dx, dy)
+			for i := range sum {
+				dst.Pix[d+i] = uint8((sum[i] + n/2) / n)
+			}
+		}
+	}
+	return dst
+}
+
+// orient applies an EXIF orientation (1-8) so the image displays upright without metadata
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var nx, ny int
+			switch orientation {
+			case 2: // mirrored
+				nx, ny = w-1-x, y
+			case 3: // rotated 180°
+				nx, ny = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				nx, ny = x, h-1-y
+			case 5: // mirrored, rotated 270° clockwise
+				nx, ny = y, x
+			case 6: // rotated 90° clockwise
+				nx, ny = h-1-y, x
+			case 7: // mirrored, rotated 90° clockwise
+				nx, ny = h-1-y, w-1-x
+			case 8: // rotated 270° clockwise
+				nx, ny = y, w-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(nx, ny):][:4], src.Pix[src.PixOffset(x, y):][:4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation returns the EXIF orientation tag of a JPEG, or 1 if it has none
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		// Metadata segments all come before the image data
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation reads the orientation tag (0x0112) from the first IFD of a TIFF structure
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			// SHORT value stored in the first two bytes of the value field
+			if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+func encodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("failed to encode JPEG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
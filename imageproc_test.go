@@ -0,0 +1,155 @@
+package flaro
+
+import (
+	"encoding/binary"
+	"image"
+	"strings"
+	"testing"
+)
+
+// tiffWithOrientation builds a TIFF header and first IFD holding an unrelated tag followed
+// by the orientation tag, in the given byte order
+func tiffWithOrientation(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 8+2+2*12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 2)
+	// ImageWidth (0x0100), LONG
+	order.PutUint16(tiff[10:], 0x0100)
+	order.PutUint16(tiff[12:], 4)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint32(tiff[18:], 640)
+	// Orientation (0x0112), SHORT
+	order.PutUint16(tiff[22:], 0x0112)
+	order.PutUint16(tiff[24:], 3)
+	order.PutUint32(tiff[26:], 1)
+	order.PutUint16(tiff[30:], orientation)
+	return tiff
+}
+
+// jpegWithSegments builds a JPEG prefix: SOI, the given APPn segments, then SOS
+func jpegWithSegments(segments ...[]byte) []byte {
+	data := []byte{0xFF, 0xD8}
+	for _, segment := range segments {
+		data = append(data, segment...)
+	}
+	return append(data, 0xFF, 0xDA, 0x00, 0x02)
+}
+
+// appSegment builds an APPn segment with payload
+func appSegment(marker byte, payload []byte) []byte {
+	segment := []byte{0xFF, marker}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(2+len(payload)))
+	return append(segment, payload...)
+}
+
+func TestExifOrientation(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		for orientation := uint16(1); orientation <= 8; orientation++ {
+			if got := exifOrientation(tiffWithOrientation(order, orientation)); got != int(orientation) {
+				t.Errorf("%v orientation %d: got %d", order, orientation, got)
+			}
+		}
+	}
+
+	noTag := tiffWithOrientation(binary.BigEndian, 6)
+	binary.BigEndian.PutUint16(noTag[8:], 1) // only the width entry
+	tests := []struct {
+		name string
+		tiff []byte
+	}{
+		{"out of range", tiffWithOrientation(binary.LittleEndian, 9)},
+		{"zero", tiffWithOrientation(binary.LittleEndian, 0)},
+		{"no orientation tag", noTag},
+		{"truncated IFD", tiffWithOrientation(binary.BigEndian, 6)[:30]},
+		{"bad byte order", append([]byte("XX"), tiffWithOrientation(binary.BigEndian, 6)[2:]...)},
+		{"IFD offset past end", append([]byte("MM\x00\x2a\x00\x00\xff\xff"), make([]byte, 8)...)},
+		{"too short", []byte("MM\x00\x2a")},
+	}
+	for _, tt := range tests {
+		if got := exifOrientation(tt.tiff); got != 1 {
+			t.Errorf("%s: got %d, want 1", tt.name, got)
+		}
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	exif := func(orientation uint16) []byte {
+		return appSegment(0xE1, append([]byte("Exif\x00\x00"), tiffWithOrientation(binary.BigEndian, orientation)...))
+	}
+	jfif := appSegment(0xE0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))
+	xmp := appSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x/>"))
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"exif only", jpegWithSegments(exif(6)), 6},
+		{"after JFIF", jpegWithSegments(jfif, exif(8)), 8},
+		{"after XMP", jpegWithSegments(xmp, exif(3)), 3},
+		{"no exif", jpegWithSegments(jfif), 1},
+		{"exif after scan", append(jpegWithSegments(jfif), exif(6)...), 1},
+		{"segment past end", jpegWithSegments(exif(6))[:20], 1},
+		{"not a JPEG", []byte("\x89PNG\r\n\x1a\n"), 1},
+		{"empty", nil, 1},
+	}
+	for _, tt := range tests {
+		if got := jpegOrientation(tt.data); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+// letterImage builds an image whose pixels carry the letters of rows in their red channel
+func letterImage(rows ...string) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, len(rows[0]), len(rows)))
+	for y, row := range rows {
+		for x := range row {
+			img.Pix[img.PixOffset(x, y)] = row[x]
+		}
+	}
+	return img
+}
+
+// imageLetters reads back the rows of an image made by letterImage
+func imageLetters(img *image.RGBA) []string {
+	rows := make([]string, img.Rect.Dy())
+	for y := range rows {
+		var b strings.Builder
+		for x := 0; x < img.Rect.Dx(); x++ {
+			b.WriteByte(img.Pix[img.PixOffset(x, y)])
+		}
+		rows[y] = b.String()
+	}
+	return rows
+}
+
+func TestOrient(t *testing.T) {
+	tests := []struct {
+		orientation int
+		want        []string
+	}{
+		{0, []string{"abc", "def"}},
+		{1, []string{"abc", "def"}},
+		{2, []string{"cba", "fed"}},     // mirrored
+		{3, []string{"fed", "cba"}},     // rotated 180°
+		{4, []string{"def", "abc"}},     // mirrored vertically
+		{5, []string{"ad", "be", "cf"}}, // transposed
+		{6, []string{"da", "eb", "fc"}}, // rotated 90° clockwise
+		{7, []string{"fc", "eb", "da"}}, // transversed
+		{8, []string{"cf", "be", "ad"}}, // rotated 90° counter-clockwise
+		{9, []string{"abc", "def"}},     // invalid, unchanged
+	}
+	for _, tt := range tests {
+		got := imageLetters(orient(letterImage("abc", "def"), tt.orientation))
+		if strings.Join(got, "/") != strings.Join(tt.want, "/") {
+			t.Errorf("orientation %d: got %v, want %v", tt.orientation, got, tt.want)
+		}
+	}
+}
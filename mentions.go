@@ -0,0 +1,180 @@
+package flaro
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GetMentions retrieves a page of what mentions userID, newest first: posts and reels listing
+// the user in their mentions, and comments whose content contains "@username". Content the
+// user created themselves is left out. Pass the previous page's NextCursor to continue; a page
+// can hold fewer than Limit mentions while NextCursor is still set.
+func (c *Client) GetMentions(accessToken, userID string, params *MentionsPageParams) (*MentionsPage, error) {
+	if params == nil {
+		params = &MentionsPageParams{}
+	}
+	limit := params.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	var cursor *timelineCursor
+	if params.Cursor != "" {
+		cur, err := decodeTimelineCursor(params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = cur
+	}
+
+	user, err := c.GetUser(accessToken, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	newQuery := func() url.Values {
+		queryParams := url.Values{}
+		queryParams.Set("select", "*")
+		queryParams.Set("order", "created_at.desc,id.desc")
+		queryParams.Set("limit", strconv.Itoa(limit))
+		if !params.Since.IsZero() {
+			queryParams.Set("created_at", "gt."+FormatTimestamp(params.Since))
+		}
+		if cursor != nil {
+			queryParams.Set("or", cursor.filter())
+		}
+		return queryParams
+	}
+
+	var events []MentionEvent
+	// Each source is only complete down to the last row of a full page; the newest of those
+	// positions is where this page has to stop
+	var complete *timelineCursor
+	fullPage := func(n int, last timelineCursor) {
+		if n == limit && (complete == nil || mentionCursorAfter(last, *complete)) {
+			complete = &last
+		}
+	}
+
+	queryParams := newQuery()
+	queryParams.Set("mentions", containsFilter(userID))
+	queryParams.Set("creator_id", "neq."+userID)
+	var posts []Post
+	if err := c.fetchJSON(accessToken, "/rest/v1/posts?"+queryParams.Encode(), "get post mentions", &posts); err != nil {
+		return nil, err
+	}
+	for i := range posts {
+		post := &posts[i]
+		events = append(events, MentionEvent{Kind: KindPost, ActorID: post.CreatorID, CreatedAt: post.CreatedAt, Post: post})
+	}
+	if len(posts) > 0 {
+		last := posts[len(posts)-1]
+		fullPage(len(posts), timelineCursor{CreatedAt: last.CreatedAt.Time, ID: last.ID})
+	}
+
+	var reels []Reel
+	if err := c.fetchJSON(accessToken, "/rest/v1/reels?"+queryParams.Encode(), "get reel mentions", &reels); err != nil {
+		return nil, err
+	}
+	for i := range reels {
+		reel := &reels[i]
+		events = append(events, MentionEvent{Kind: KindReel, ActorID: reel.CreatorID, CreatedAt: reel.CreatedAt, Reel: reel})
+	}
+	if len(reels) > 0 {
+		last := reels[len(reels)-1]
+		fullPage(len(reels), timelineCursor{CreatedAt: last.CreatedAt.Time, ID: last.ID})
+	}
+
+	if user.Username != "" {
+		queryParams := newQuery()
+		queryParams.Set("content", "ilike.%@"+escapeLikePattern(user.Username)+"%")
+		queryParams.Set("user_id", "neq."+userID)
+		var comments []Comment
+		if err := c.fetchJSON(accessToken, "/rest/v1/comments?"+queryParams.Encode(), "get comment mentions", &comments); err != nil {
+			return nil, err
+		}
+		for i := range comments {
+			comment := &comments[i]
+			// ilike also matches longer usernames that start with this one
+			if !mentionsUsername(comment.Content, user.Username) {
+				continue
+			}
+			events = append(events, MentionEvent{Kind: KindComment, ActorID: comment.UserID, CreatedAt: comment.CreatedAt, Comment: comment})
+		}
+		if len(comments) > 0 {
+			last := comments[len(comments)-1]
+			fullPage(len(comments), timelineCursor{CreatedAt: last.CreatedAt.Time, ID: last.ID})
+		}
+	}
+
+	sortMentionsNewestFirst(events)
+	page := &MentionsPage{Mentions: []MentionEvent{}}
+	for _, event := range events {
+		if complete != nil && mentionCursorAfter(*complete, mentionCursor(event)) {
+			break
+		}
+		if len(page.Mentions) == limit {
+			last := mentionCursor(page.Mentions[limit-1])
+			complete = &last
+			break
+		}
+		page.Mentions = append(page.Mentions, event)
+	}
+	if complete != nil {
+		page.NextCursor = encodeTimelineCursor(*complete)
+	}
+	return page, nil
+}
+
+// mentionsUsername reports whether content contains "@username" as a whole mention,
+// ignoring case
+func mentionsUsername(content, username string) bool {
+	for i := strings.IndexByte(content, '@'); i >= 0; {
+		content = content[i+1:]
+		end := 0
+		for end < len(content) && isUsernameChar(content[end]) {
+			end++
+		}
+		// Usernames can't end with a period, so one there ends the sentence
+		if strings.EqualFold(strings.TrimRight(content[:end], "."), username) {
+			return true
+		}
+		i = strings.IndexByte(content, '@')
+	}
+	return false
+}
+
+// isUsernameChar reports whether b may appear in a username
+func isUsernameChar(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b == '_' || b == '.'
+}
+
+// sortMentionsNewestFirst orders events newest first, ties broken by ID like the queries
+func sortMentionsNewestFirst(events []MentionEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return mentionCursorAfter(mentionCursor(events[i]), mentionCursor(events[j]))
+	})
+}
+
+// mentionCursor returns the paging position of event
+func mentionCursor(event MentionEvent) timelineCursor {
+	cur := timelineCursor{CreatedAt: event.CreatedAt.Time}
+	switch {
+	case event.Post != nil:
+		cur.ID = event.Post.ID
+	case event.Reel != nil:
+		cur.ID = event.Reel.ID
+	case event.Comment != nil:
+		cur.ID = event.Comment.ID
+	}
+	return cur
+}
+
+// mentionCursorAfter reports whether a comes before b in newest-first order
+func mentionCursorAfter(a, b timelineCursor) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.ID > b.ID
+}
@@ -0,0 +1,152 @@
+package flaro
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// ObjectNaming selects how UploadImageFrom and UploadVideoFrom name uploaded objects
+type ObjectNaming int
+
+const (
+	// NamingRandom names objects uploads/<uuid><ext>, so concurrent uploads never collide
+	NamingRandom ObjectNaming = iota
+	// NamingContentHash names objects uploads/<sha256><ext>, so identical files share a name
+	NamingContentHash
+	// NamingTimestamp uses the app's uploads/<unix-ms>-0 (images) and uploads/<unix-ms> (videos)
+	// names, which collide when two uploads start in the same millisecond
+	NamingTimestamp
+)
+
+// uploadObjectPath picks the object path for an upload to bucket and, when the name depends
+// on the content, hashes it first. It returns the reader to upload from, which replaces r,
+// and fills in opts.ContentType if it was empty.
+func (c *Client) uploadObjectPath(r io.Reader, opts *UploadOptions, timestampName string) (string, io.Reader, error) {
+	naming := opts.Naming
+	if opts.Dedup {
+		naming = NamingContentHash
+	}
+
+	var sum string
+	if naming == NamingContentHash {
+		var err error
+		if sum, r, err = hashUpload(r, opts.MaxSize); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if opts.ContentType == "" {
+		br := bufio.NewReaderSize(r, 512)
+		// Peek returns what it could read on a short file; the error is reported by the upload
+		head, _ := br.Peek(512)
+		if len(head) == 0 {
+			return "", nil, fmt.Errorf("upload is empty")
+		}
+		opts.ContentType = http.DetectContentType(head)
+		r = br
+	}
+
+	ext := objectExtension(opts.Filename, opts.ContentType)
+	switch naming {
+	case NamingContentHash:
+		return "uploads/" + sum + ext, r, nil
+	case NamingTimestamp:
+		return "uploads/" + timestampName, r, nil
+	}
+	id, err := newUUID()
+	if err != nil {
+		return "", nil, err
+	}
+	return "uploads/" + id + ext, r, nil
+}
+
+// hashUpload returns the hex SHA-256 of r and a reader positioned at the start of the same
+// data. Seekable readers are rewound after hashing; anything else is buffered in memory,
+// up to maxSize bytes.
+func hashUpload(r io.Reader, maxSize int64) (string, io.Reader, error) {
+	h := sha256.New()
+	// Read one byte past the limit so an oversized file is detected rather than silently truncated
+	limit := func(r io.Reader) io.Reader {
+		if maxSize > 0 {
+			return io.LimitReader(r, maxSize+1)
+		}
+		return r
+	}
+
+	if rs, ok := r.(io.ReadSeeker); ok {
+		start, err := rs.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to seek upload: %w", err)
+		}
+		n, err := io.Copy(h, limit(rs))
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to hash upload: %w", err)
+		}
+		if maxSize > 0 && n > maxSize {
+			return "", nil, fmt.Errorf("%w of %d bytes", ErrUploadTooLarge, maxSize)
+		}
+		if _, err := rs.Seek(start, io.SeekStart); err != nil {
+			return "", nil, fmt.Errorf("failed to seek upload: %w", err)
+		}
+		return hex.EncodeToString(h.Sum(nil)), rs, nil
+	}
+
+	data, err := io.ReadAll(limit(r))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if maxSize > 0 && int64(len(data)) > maxSize {
+		return "", nil, fmt.Errorf("%w of %d bytes", ErrUploadTooLarge, maxSize)
+	}
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), bytes.NewReader(data), nil
+}
+
+// objectExtension returns the extension to keep in an object name: the one of filename if
+// it has one, otherwise the usual one for contentType
+func objectExtension(filename, contentType string) string {
+	if ext := strings.ToLower(filepath.Ext(filename)); ext != "" {
+		return ext
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	case "video/mp4":
+		return ".mp4"
+	case "video/quicktime":
+		return ".mov"
+	case "video/webm":
+		return ".webm"
+	}
+	if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate object name: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
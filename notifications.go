@@ -0,0 +1,403 @@
+package flaro
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultNotificationLookback is how far back the first Poll looks for comments, mentions
+	// and system messages when there is no stored state
+	DefaultNotificationLookback = 24 * time.Hour
+	// notificationMentionsPageSize is how many mentions Poll requests at a time
+	notificationMentionsPageSize = 100
+)
+
+// NotificationKind identifies what a Notification is about
+type NotificationKind string
+
+const (
+	NotificationLike    NotificationKind = "like"    // Someone liked one of the user's posts
+	NotificationComment NotificationKind = "comment" // Someone commented on one of the user's posts or reels
+	NotificationReply   NotificationKind = "reply"   // Someone replied to one of the user's comments
+	NotificationFollow  NotificationKind = "follow"  // Someone started following the user
+	NotificationMention NotificationKind = "mention" // Someone mentioned the user
+	NotificationSystem  NotificationKind = "system"  // An unread system message
+)
+
+// NotificationState is what a Notifications feed remembers between polls
+type NotificationState struct {
+	Watermark time.Time           `json:"watermark"`          // Start of the last poll
+	PostLikes map[string][]string `json:"post_likes"`         // Likers of each post at the last poll
+	Followers []string            `json:"followers"`          // Followers at the last poll
+	Reported  []string            `json:"reported,omitempty"` // Notifications already returned that were created after Watermark
+}
+
+// NotificationStore persists NotificationState per user so a feed continues where it left
+// off after a restart
+type NotificationStore interface {
+	Get(userID string) (*NotificationState, error) // nil, nil if unknown
+	Set(userID string, state NotificationState) error
+}
+
+// MemoryNotificationStore keeps notification state in memory
+type MemoryNotificationStore struct {
+	mu     sync.Mutex
+	states map[string]NotificationState
+}
+
+// NewMemoryNotificationStore creates an empty in-memory notification store
+func NewMemoryNotificationStore() *MemoryNotificationStore {
+	return &MemoryNotificationStore{states: make(map[string]NotificationState)}
+}
+
+// Get implements NotificationStore
+func (s *MemoryNotificationStore) Get(userID string) (*NotificationState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// Set implements NotificationStore
+func (s *MemoryNotificationStore) Set(userID string, state NotificationState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[userID] = state
+	return nil
+}
+
+// FileNotificationStore keeps notification state as one JSON file per user in Dir
+type FileNotificationStore struct {
+	Dir string
+}
+
+// Get implements NotificationStore
+func (s FileNotificationStore) Get(userID string) (*NotificationState, error) {
+	data, err := os.ReadFile(s.path(userID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification state: %w", err)
+	}
+	var state NotificationState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse notification state: %w", err)
+	}
+	return &state, nil
+}
+
+// Set implements NotificationStore. The file is replaced atomically so a crash never leaves a torn state.
+func (s FileNotificationStore) Set(userID string, state NotificationState) error {
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create notification state directory: %w", err)
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification state: %w", err)
+	}
+	tmp := s.path(userID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write notification state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path(userID)); err != nil {
+		return fmt.Errorf("failed to write notification state: %w", err)
+	}
+	return nil
+}
+
+func (s FileNotificationStore) path(userID string) string {
+	return filepath.Join(s.Dir, url.PathEscape(userID)+".json")
+}
+
+// NotificationOptions configures a Notifications feed. Zero values pick the defaults.
+type NotificationOptions struct {
+	Store    NotificationStore // Where seen state is kept (default: a new MemoryNotificationStore)
+	Lookback time.Duration     // How far back the first poll looks (default DefaultNotificationLookback)
+}
+
+// Notifications computes a user's notifications from the regular endpoints. Each Poll
+// returns what happened since the previous one: new likes and followers are found by
+// comparing with the state saved by the last poll, comments, replies, mentions and system
+// messages by their creation time. Items created while a poll runs are returned by that poll
+// and remembered so the next one skips them. The first poll without saved state only
+// records likes and followers as a baseline.
+type Notifications struct {
+	client      *Client
+	userID      string
+	opts        NotificationOptions
+	mu          sync.Mutex
+	accessToken string
+}
+
+// Notifications creates a notification feed for userID
+func (c *Client) Notifications(accessToken, userID string, opts NotificationOptions) *Notifications {
+	if opts.Store == nil {
+		opts.Store = NewMemoryNotificationStore()
+	}
+	if opts.Lookback <= 0 {
+		opts.Lookback = DefaultNotificationLookback
+	}
+	return &Notifications{client: c, userID: userID, opts: opts, accessToken: accessToken}
+}
+
+// SetAccessToken replaces the token used by later polls, e.g. after RefreshToken
+func (n *Notifications) SetAccessToken(accessToken string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.accessToken = accessToken
+}
+
+// Poll returns the notifications since the previous poll, newest first, and saves the new
+// state to the store. Nothing is saved if any query fails, so the next poll retries.
+func (n *Notifications) Poll() ([]Notification, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	c, accessToken := n.client, n.accessToken
+	started := c.now().Round(0)
+	saved, err := n.opts.Store.Get(n.userID)
+	if err != nil {
+		return nil, err
+	}
+	baseline := saved == nil
+	if baseline {
+		saved = &NotificationState{Watermark: started.Add(-n.opts.Lookback)}
+	}
+	since := saved.Watermark
+	next := NotificationState{Watermark: started, PostLikes: make(map[string][]string)}
+	var notifications []Notification
+
+	posts, err := c.GetUserPosts(accessToken, n.userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts: %w", err)
+	}
+	postIDs := make([]string, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+		next.PostLikes[post.ID] = post.Likes
+		if baseline {
+			continue
+		}
+		before := stringSet(saved.PostLikes[post.ID])
+		for _, liker := range post.Likes {
+			if before[liker] || liker == n.userID {
+				continue
+			}
+			notifications = append(notifications, Notification{
+				Kind:      NotificationLike,
+				ID:        "like:" + post.ID + ":" + liker,
+				ActorID:   liker,
+				PostID:    post.ID,
+				CreatedAt: NewTimestamp(started),
+			})
+		}
+	}
+
+	followers, err := c.GetFollowers(accessToken, n.userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get followers: %w", err)
+	}
+	before := stringSet(saved.Followers)
+	for _, f := range followers {
+		next.Followers = append(next.Followers, f.FollowerID)
+		if baseline || before[f.FollowerID] {
+			continue
+		}
+		notifications = append(notifications, Notification{
+			Kind:      NotificationFollow,
+			ID:        "follow:" + f.FollowerID,
+			ActorID:   f.FollowerID,
+			CreatedAt: NewTimestamp(started),
+		})
+	}
+
+	reels, err := c.GetUserReels(accessToken, n.userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reels: %w", err)
+	}
+	reelIDs := make([]string, 0, len(reels))
+	for _, reel := range reels {
+		reelIDs = append(reelIDs, reel.ID)
+	}
+
+	// Comments, mentions and system messages are found by creation time
+	timed, err := n.newComments(accessToken, postIDs, reelIDs, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var mentions []MentionEvent
+	params := &MentionsPageParams{Limit: notificationMentionsPageSize, Since: since}
+	for {
+		page, err := c.GetMentions(accessToken, n.userID, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get mentions: %w", err)
+		}
+		mentions = append(mentions, page.Mentions...)
+		if page.NextCursor == "" {
+			break
+		}
+		params.Cursor = page.NextCursor
+	}
+	for _, m := range mentions {
+		notification := Notification{Kind: NotificationMention, ActorID: m.ActorID, CreatedAt: m.CreatedAt}
+		switch {
+		case m.Post != nil:
+			notification.ID, notification.PostID = "mention:post:"+m.Post.ID, m.Post.ID
+		case m.Reel != nil:
+			notification.ID, notification.ReelID = "mention:reel:"+m.Reel.ID, m.Reel.ID
+		case m.Comment != nil:
+			notification.ID, notification.CommentID = "mention:comment:"+m.Comment.ID, m.Comment.ID
+			notification.PostID = m.Comment.PostID
+			notification.Text = m.Comment.Content
+		}
+		timed = append(timed, notification)
+	}
+
+	messages, err := c.GetUnreadSystemMessages(accessToken, n.userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system messages: %w", err)
+	}
+	for _, msg := range messages {
+		if !msg.CreatedAt.After(since) {
+			continue
+		}
+		timed = append(timed, Notification{
+			Kind:            NotificationSystem,
+			ID:              "system:" + strconv.Itoa(msg.ID),
+			SystemMessageID: msg.ID,
+			Text:            msg.Title,
+			CreatedAt:       msg.CreatedAt,
+		})
+	}
+
+	// The queries ran after started, so anything newer comes back again next poll
+	reported := stringSet(saved.Reported)
+	for _, notification := range timed {
+		if notification.CreatedAt.After(started) {
+			next.Reported = append(next.Reported, notification.ID)
+		}
+		if !reported[notification.ID] {
+			notifications = append(notifications, notification)
+		}
+	}
+
+	if err := n.opts.Store.Set(n.userID, next); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(notifications, func(i, j int) bool {
+		return notifications[i].CreatedAt.After(notifications[j].CreatedAt.Time)
+	})
+	return notifications, nil
+}
+
+// HandleRealtime polls again when env reports a database change that can produce
+// notifications, e.g. from a RealtimeClient subscribed with SubscribePostsForCreator for
+// the user's own posts. Other frames (replies, heartbeats) return no notifications.
+func (n *Notifications) HandleRealtime(env *RealtimeEnvelope) ([]Notification, error) {
+	var payload struct {
+		Data struct {
+			Table string `json:"table"`
+		} `json:"data"`
+	}
+	if err := env.UnmarshalPayload(&payload); err != nil {
+		return nil, err
+	}
+	switch payload.Data.Table {
+	case "posts", "comments", "follows", "system_messages":
+		return n.Poll()
+	}
+	return nil, nil
+}
+
+// newComments finds comments on postIDs and reelIDs and replies to the user's comments
+// created after since, leaving out the user's own comments
+func (n *Notifications) newComments(accessToken string, postIDs, reelIDs []string, since time.Time) ([]Notification, error) {
+	c := n.client
+	var notifications []Notification
+	seen := make(map[string]bool)
+
+	user, err := c.GetUser(accessToken, n.userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Username != "" {
+		queryParams := url.Values{}
+		queryParams.Set("select", "*")
+		queryParams.Set("reply_to_username", "eq."+user.Username)
+		queryParams.Set("user_id", "neq."+n.userID)
+		queryParams.Set("created_at", "gt."+FormatTimestamp(since))
+		var replies []Comment
+		if err := c.fetchJSON(accessToken, "/rest/v1/comments?"+queryParams.Encode(), "get replies", &replies); err != nil {
+			return nil, err
+		}
+		for _, reply := range replies {
+			seen[reply.ID] = true
+			notifications = append(notifications, commentNotification(NotificationReply, reply))
+		}
+	}
+
+	for _, target := range []struct {
+		column string
+		ids    []string
+	}{{"post_id", postIDs}, {"reel_id", reelIDs}} {
+		for _, chunk := range chunkStrings(target.ids, maxInFilterIDs) {
+			queryParams := url.Values{}
+			queryParams.Set("select", "*")
+			queryParams.Set(target.column, inFilter(chunk))
+			queryParams.Set("user_id", "neq."+n.userID)
+			queryParams.Set("created_at", "gt."+FormatTimestamp(since))
+			var comments []Comment
+			if err := c.fetchJSON(accessToken, "/rest/v1/comments?"+queryParams.Encode(), "get comments", &comments); err != nil {
+				return nil, err
+			}
+			for _, comment := range comments {
+				// A reply to the user on their own post or reel is reported once, as a reply
+				if !seen[comment.ID] {
+					notifications = append(notifications, commentNotification(NotificationComment, comment))
+				}
+			}
+		}
+	}
+	return notifications, nil
+}
+
+// commentNotification builds a comment or reply notification
+func commentNotification(kind NotificationKind, comment Comment) Notification {
+	notification := Notification{
+		Kind:      kind,
+		ID:        string(kind) + ":" + comment.ID,
+		ActorID:   comment.UserID,
+		PostID:    comment.PostID,
+		CommentID: comment.ID,
+		Text:      comment.Content,
+		CreatedAt: comment.CreatedAt,
+	}
+	if comment.ReelID != nil {
+		notification.ReelID = *comment.ReelID
+	}
+	return notification
+}
+
+// stringSet returns the values as a set
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,116 @@
+package flaro
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxInFilterIDs caps the number of values in a single "in.(...)" filter so request URLs
+// stay well below common proxy limits (UUIDs are 36 characters each)
+const maxInFilterIDs = 50
+
+// fetchJSON performs a GET against a REST endpoint and decodes the JSON response into out.
+// operation is used in error messages, e.g. "get home timeline".
+func (c *Client) fetchJSON(accessToken, endpoint, operation string, out interface{}) error {
+	resp, err := c.makeAuthenticatedRequest("GET", endpoint, nil, accessToken)
+	if err != nil {
+		return fmt.Errorf("failed to make %s request: %w", operation, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Check for errors
+	if resp.StatusCode != 200 {
+		var apiErr APIError
+		if err := json.Unmarshal(body, &apiErr); err != nil {
+			return fmt.Errorf("%s failed with status %d: %s", operation, resp.StatusCode, string(body))
+		}
+		return &apiErr
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", operation, err)
+	}
+	return nil
+}
+
+// inFilter builds a PostgREST "in.(...)" filter value, quoting values with reserved characters
+func inFilter(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = quoteFilterValue(v)
+	}
+	return "in.(" + strings.Join(quoted, ",") + ")"
+}
+
+// quoteFilterValue double-quotes a value for use inside PostgREST list or logic filters
+// when it contains characters that PostgREST treats as separators
+func quoteFilterValue(v string) string {
+	if !strings.ContainsAny(v, `,.:()" \`) {
+		return v
+	}
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	return `"` + v + `"`
+}
+
+// containsFilter builds a PostgREST "cs.{...}" (array contains) filter value
+func containsFilter(values ...string) string {
+	return "cs." + arrayLiteral(values)
+}
+
+// arrayLiteral formats values as a Postgres array literal, quoting elements the way
+// Postgres requires
+func arrayLiteral(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		if v == "" || strings.EqualFold(v, "null") || strings.ContainsAny(v, `,{}" \`) {
+			v = strings.ReplaceAll(v, `\`, `\\`)
+			v = strings.ReplaceAll(v, `"`, `\"`)
+			v = `"` + v + `"`
+		}
+		quoted[i] = v
+	}
+	return "{" + strings.Join(quoted, ",") + "}"
+}
+
+// overlapsFilter builds a PostgREST "ov.{...}" (array overlaps) filter value
+func overlapsFilter(values ...string) string {
+	return "ov." + arrayLiteral(values)
+}
+
+// chunkStrings splits values into consecutive chunks of at most size elements
+func chunkStrings(values []string, size int) [][]string {
+	if size <= 0 {
+		size = maxInFilterIDs
+	}
+	chunks := make([][]string, 0, (len(values)+size-1)/size)
+	for start := 0; start < len(values); start += size {
+		end := start + size
+		if end > len(values) {
+			end = len(values)
+		}
+		chunks = append(chunks, values[start:end])
+	}
+	return chunks
+}
+
+// uniqueStrings returns values with duplicates and empty strings removed, preserving order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,301 @@
+package flaro
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxProfilePictureSize is the largest image SetProfilePicture uploads
+const MaxProfilePictureSize = 5 << 20 // 5 MiB
+
+// SetProfileLimits sets the client-side checks UpdateProfile applies. The zero value, the
+// default, only checks that fields are well-formed.
+func (c *Client) SetProfileLimits(limits ProfileLimits) {
+	c.profileLimits = limits
+}
+
+// profileField is a single column update in a profile PATCH
+type profileField struct {
+	key   string
+	value interface{}
+}
+
+// fields returns the columns set in the patch. Username comes first so a taken username
+// fails before anything else is changed when fields are sent one by one.
+func (p ProfilePatch) fields() []profileField {
+	var fields []profileField
+	if p.Username != nil {
+		fields = append(fields, profileField{"username", *p.Username})
+	}
+	if p.DisplayName != nil {
+		fields = append(fields, profileField{"display_name", strings.TrimSpace(*p.DisplayName)})
+	}
+	if p.Bio != nil {
+		fields = append(fields, profileField{"bio", *p.Bio})
+	}
+	if p.ProfilePicture != nil {
+		fields = append(fields, profileField{"profile_picture", *p.ProfilePicture})
+	}
+	if p.Website != nil {
+		if *p.Website == "" {
+			fields = append(fields, profileField{"website", nil})
+		} else {
+			fields = append(fields, profileField{"website", *p.Website})
+		}
+	}
+	if p.IsPrivate != nil {
+		fields = append(fields, profileField{"is_private", *p.IsPrivate})
+	}
+	return fields
+}
+
+// IsEmpty reports whether the patch changes nothing
+func (p ProfilePatch) IsEmpty() bool {
+	return len(p.fields()) == 0
+}
+
+// Validate checks that every set field is well-formed and within limits (zero limits are
+// not checked). Errors wrap ErrInvalidProfile, or ErrInvalidUsername for the username.
+func (p ProfilePatch) Validate(limits ProfileLimits) error {
+	if p.Username != nil {
+		if *p.Username == "" {
+			return fmt.Errorf("%w: cannot be empty", ErrInvalidUsername)
+		}
+		if limits.ValidateUsername {
+			if err := ValidateUsername(*p.Username); err != nil {
+				return err
+			}
+		}
+	}
+	if p.DisplayName != nil {
+		name := strings.TrimSpace(*p.DisplayName)
+		if name == "" {
+			return fmt.Errorf("%w: display name cannot be empty", ErrInvalidProfile)
+		}
+		if limits.MaxDisplayNameLength > 0 && utf8.RuneCountInString(name) > limits.MaxDisplayNameLength {
+			return fmt.Errorf("%w: display name must be at most %d characters", ErrInvalidProfile, limits.MaxDisplayNameLength)
+		}
+	}
+	if p.Bio != nil && limits.MaxBioLength > 0 && utf8.RuneCountInString(*p.Bio) > limits.MaxBioLength {
+		return fmt.Errorf("%w: bio must be at most %d characters", ErrInvalidProfile, limits.MaxBioLength)
+	}
+	if p.ProfilePicture != nil {
+		if err := validateHTTPURL(*p.ProfilePicture); err != nil {
+			return fmt.Errorf("%w: profile picture %v", ErrInvalidProfile, err)
+		}
+	}
+	if p.Website != nil && *p.Website != "" {
+		if limits.MaxWebsiteLength > 0 && len(*p.Website) > limits.MaxWebsiteLength {
+			return fmt.Errorf("%w: website must be at most %d characters", ErrInvalidProfile, limits.MaxWebsiteLength)
+		}
+		if err := validateHTTPURL(*p.Website); err != nil {
+			return fmt.Errorf("%w: website %v", ErrInvalidProfile, err)
+		}
+	}
+	return nil
+}
+
+// validateHTTPURL checks that raw is an absolute http(s) URL with a host
+func validateHTTPURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("is not a valid URL: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("must be an http or https URL")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("must include a host")
+	}
+	return nil
+}
+
+// UpdateProfile validates and applies a ProfilePatch, returning the updated profile.
+// All fields are sent in one request; if the server refuses it for updating more than one
+// field, they are retried one at a time (a failure part way leaves the earlier fields
+// applied). Any other error, such as an unknown column or a bad value, is returned as is.
+// A username change also sets username_updated_at, and is checked against the client's
+// ProfileLimits.UsernameChangeCooldown (if set) using UsernameUpdatedAt.
+func (c *Client) UpdateProfile(accessToken, userID string, patch ProfilePatch) (*UserProfile, error) {
+	if patch.IsEmpty() {
+		return nil, fmt.Errorf("profile patch has no fields to update")
+	}
+	limits := c.profileLimits
+	if err := patch.Validate(limits); err != nil {
+		return nil, err
+	}
+
+	if patch.Username != nil {
+		c.profiles.Invalidate(userID)
+		current, err := c.GetUser(accessToken, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current profile: %w", err)
+		}
+		if current.Username == *patch.Username {
+			// Unchanged username: don't send it and don't trip the cooldown
+			patch.Username = nil
+		} else if err := checkUsernameCooldown(current, limits.UsernameChangeCooldown, c.now()); err != nil {
+			return nil, err
+		}
+		if patch.IsEmpty() {
+			return current, nil
+		}
+	}
+
+	fields := patch.fields()
+	if patch.Username != nil {
+		// Record the change so cooldown checks, here and in the app, see it. Username is
+		// the first field, so this follows it when fields are sent one at a time.
+		changedAt := profileField{"username_updated_at", FormatTimestamp(c.now())}
+		fields = append(fields[:1], append([]profileField{changedAt}, fields[1:]...)...)
+	}
+	if len(fields) > 1 {
+		body := make(map[string]interface{}, len(fields))
+		for _, f := range fields {
+			body[f.key] = f.value
+		}
+		profile, status, err := c.patchProfile(accessToken, userID, body)
+		if err == nil {
+			return profile, nil
+		}
+		if status != 400 || !isMultiFieldRefusal(err) {
+			return nil, err
+		}
+	}
+
+	var profile *UserProfile
+	for _, f := range fields {
+		var err error
+		profile, _, err = c.patchProfile(accessToken, userID, map[string]interface{}{f.key: f.value})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update %s: %w", f.key, err)
+		}
+	}
+	return profile, nil
+}
+
+// isMultiFieldRefusal reports whether err is the users table refusing to change more than
+// one field per update. The rule is enforced by the database, which raises it as a plain
+// exception (P0001) or words it as a one-field limit; unknown columns (PGRST204), bad
+// values (22xxx) and constraint failures (23xxx) are not it.
+func isMultiFieldRefusal(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch {
+	case apiErr.Code == "P0001":
+		return true
+	case strings.HasPrefix(apiErr.Code, "PGRST"), strings.HasPrefix(apiErr.Code, "22"), strings.HasPrefix(apiErr.Code, "23"):
+		return false
+	}
+	message := strings.ToLower(apiErr.Message)
+	for _, hint := range []string{"one field", "one column", "multiple fields", "multiple columns", "at a time"} {
+		if strings.Contains(message, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkUsernameCooldown returns an error wrapping ErrUsernameCooldown if the profile's
+// username was changed less than cooldown before now. A zero cooldown never fails.
+func checkUsernameCooldown(profile *UserProfile, cooldown time.Duration, now time.Time) error {
+	if cooldown <= 0 || profile.UsernameUpdatedAt == nil || profile.UsernameUpdatedAt.IsZero() {
+		return nil
+	}
+	if next := profile.UsernameUpdatedAt.Add(cooldown); now.Before(next) {
+		return fmt.Errorf("%w: next change allowed at %s", ErrUsernameCooldown, next.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// patchProfile sends a PATCH for the given columns and returns the updated row and HTTP status
+func (c *Client) patchProfile(accessToken, userID string, body map[string]interface{}) (*UserProfile, int, error) {
+	endpoint := "/rest/v1/users?user_id=eq." + url.QueryEscape(userID)
+	headers := map[string]string{"Prefer": "return=representation"}
+	resp, err := c.makeRequestWithHeaders("PATCH", endpoint, body, accessToken, headers)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to update profile: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode == 409 {
+		return nil, resp.StatusCode, fmt.Errorf("update profile failed: %w", ErrUsernameTaken)
+	}
+
+	// Check for errors
+	if resp.StatusCode != 200 {
+		var apiErr APIError
+		if err := json.Unmarshal(respBody, &apiErr); err != nil {
+			return nil, resp.StatusCode, fmt.Errorf("update profile failed with status %d: %s", resp.StatusCode, string(respBody))
+		}
+		return nil, resp.StatusCode, fmt.Errorf("update profile failed: %w", &apiErr)
+	}
+
+	c.profiles.Invalidate(userID)
+
+	var profiles []UserProfile
+	if err := json.Unmarshal(respBody, &profiles); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to parse update profile response: %w", err)
+	}
+	// No rows means the user doesn't exist or row level security hid it from this token
+	if len(profiles) == 0 {
+		return nil, resp.StatusCode, fmt.Errorf("update profile failed: user %w", ErrNotFound)
+	}
+
+	c.profiles.Put(profiles[0])
+	return &profiles[0], resp.StatusCode, nil
+}
+
+// SetProfilePicture uploads an image to the profile-pictures bucket and sets it as the
+// user's profile picture, returning the updated profile. The image must be a JPEG, PNG,
+// GIF or WebP of at most MaxProfilePictureSize bytes. If the profile update fails the
+// uploaded object is deleted again.
+func (c *Client) SetProfilePicture(accessToken, userID string, image io.Reader) (*UserProfile, error) {
+	data, err := io.ReadAll(io.LimitReader(image, MaxProfilePictureSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: profile picture is empty", ErrInvalidProfile)
+	}
+	if len(data) > MaxProfilePictureSize {
+		return nil, fmt.Errorf("%w: profile picture must be at most %d bytes", ErrInvalidProfile, MaxProfilePictureSize)
+	}
+	contentType, _, err := detectImageType(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProfile, err)
+	}
+
+	// Same naming as the app: uploads/<timestamp>
+	objectPath := "uploads/" + c.now().UTC().Format("2006-01-02T15:04:05.000000")
+	opts := UploadOptions{CacheControl: 3600, ContentType: contentType, Size: int64(len(data))}
+	if _, err := c.uploadStream(context.Background(), accessToken, ProfilePicturesBucket, objectPath, bytes.NewReader(data), opts); err != nil {
+		return nil, fmt.Errorf("failed to upload profile picture: %w", err)
+	}
+
+	pictureURL := c.publicObjectURL(ProfilePicturesBucket, objectPath)
+	profile, err := c.UpdateProfile(accessToken, userID, ProfilePatch{ProfilePicture: &pictureURL})
+	if err != nil {
+		if cleanupErr := c.deleteObject(accessToken, ProfilePicturesBucket, objectPath); cleanupErr != nil {
+			return nil, errors.Join(fmt.Errorf("failed to set profile picture: %w", err), fmt.Errorf("failed to remove uploaded picture: %w", cleanupErr))
+		}
+		return nil, fmt.Errorf("failed to set profile picture: %w", err)
+	}
+
+	return profile, nil
+}
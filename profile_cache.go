@@ -0,0 +1,202 @@
+package flaro
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultProfileCacheSize is the number of profiles kept by the client's default cache
+	DefaultProfileCacheSize = 1000
+	// DefaultProfileCacheTTL is how long a cached profile is served before it is fetched again
+	DefaultProfileCacheTTL = 5 * time.Minute
+)
+
+// ProfileCache is a concurrency-safe LRU cache of user profiles with a per-entry TTL.
+// It is shared by GetUser, GetUsers, SearchUsers and the author embedding helpers.
+type ProfileCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	order    *list.List // front = most recently used
+	entries  map[string]*list.Element
+	now      func() time.Time
+}
+
+// profileCacheEntry is the value stored in each list element
+type profileCacheEntry struct {
+	profile   UserProfile
+	expiresAt time.Time
+}
+
+// NewProfileCache creates a profile cache holding up to capacity profiles for ttl each.
+// A non-positive capacity or ttl falls back to the defaults.
+func NewProfileCache(capacity int, ttl time.Duration) *ProfileCache {
+	if capacity <= 0 {
+		capacity = DefaultProfileCacheSize
+	}
+	if ttl <= 0 {
+		ttl = DefaultProfileCacheTTL
+	}
+	return &ProfileCache{
+		capacity: capacity,
+		ttl:      ttl,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// SetClock replaces the clock used for expiry. Passing nil restores the system clock.
+// The client's default cache already follows the client's clock.
+func (pc *ProfileCache) SetClock(clock Clock) {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.now = clock.Now
+}
+
+// Get returns a copy of the cached profile for userID if present and not expired
+func (pc *ProfileCache) Get(userID string) (*UserProfile, bool) {
+	if pc == nil {
+		return nil, false
+	}
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	el, ok := pc.entries[userID]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*profileCacheEntry)
+	if !pc.now().Before(entry.expiresAt) {
+		pc.order.Remove(el)
+		delete(pc.entries, userID)
+		return nil, false
+	}
+	pc.order.MoveToFront(el)
+	profile := cloneProfile(entry.profile)
+	return &profile, true
+}
+
+// Put stores a copy of profile, evicting the least recently used entry when full
+func (pc *ProfileCache) Put(profile UserProfile) {
+	if pc == nil || profile.UserID == "" {
+		return
+	}
+	profile = cloneProfile(profile)
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	expiresAt := pc.now().Add(pc.ttl)
+	if el, ok := pc.entries[profile.UserID]; ok {
+		el.Value = &profileCacheEntry{profile: profile, expiresAt: expiresAt}
+		pc.order.MoveToFront(el)
+		return
+	}
+
+	pc.entries[profile.UserID] = pc.order.PushFront(&profileCacheEntry{profile: profile, expiresAt: expiresAt})
+	for pc.order.Len() > pc.capacity {
+		oldest := pc.order.Back()
+		pc.order.Remove(oldest)
+		delete(pc.entries, oldest.Value.(*profileCacheEntry).profile.UserID)
+	}
+}
+
+// cloneProfile copies profile including the values its slice and pointer fields refer to,
+// so callers and the cache never share them
+func cloneProfile(profile UserProfile) UserProfile {
+	if profile.Website != nil {
+		website := *profile.Website
+		profile.Website = &website
+	}
+	if profile.UsernameUpdatedAt != nil {
+		updatedAt := *profile.UsernameUpdatedAt
+		profile.UsernameUpdatedAt = &updatedAt
+	}
+	if profile.PremiumExpires != nil {
+		expires := *profile.PremiumExpires
+		profile.PremiumExpires = &expires
+	}
+	if profile.Ranks != nil {
+		profile.Ranks = append([]Rank{}, profile.Ranks...)
+	}
+	return profile
+}
+
+// Invalidate removes the cached profile for userID, e.g. after the profile was updated
+func (pc *ProfileCache) Invalidate(userID string) {
+	if pc == nil {
+		return
+	}
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	if el, ok := pc.entries[userID]; ok {
+		pc.order.Remove(el)
+		delete(pc.entries, userID)
+	}
+}
+
+// Purge removes every cached profile
+func (pc *ProfileCache) Purge() {
+	if pc == nil {
+		return
+	}
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	pc.order.Init()
+	pc.entries = make(map[string]*list.Element)
+}
+
+// Len returns the number of cached profiles, including ones that have expired but not yet been evicted
+func (pc *ProfileCache) Len() int {
+	if pc == nil {
+		return 0
+	}
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	return pc.order.Len()
+}
+
+// ProfileCache returns the client's profile cache (nil if caching is disabled)
+func (c *Client) ProfileCache() *ProfileCache {
+	return c.profiles
+}
+
+// SetProfileCache replaces the client's profile cache. Pass nil to disable caching.
+func (c *Client) SetProfileCache(cache *ProfileCache) {
+	c.profiles = cache
+}
+
+// cacheProfiles stores each profile in the client's cache
+func (c *Client) cacheProfiles(profiles ...*UserProfile) {
+	for _, p := range profiles {
+		if p != nil {
+			c.profiles.Put(*p)
+		}
+	}
+}
+
+// profileFromSearchUser converts a search result into a UserProfile
+func profileFromSearchUser(u SearchUser) UserProfile {
+	return UserProfile{
+		UserID:            u.UserID,
+		Username:          u.Username,
+		DisplayName:       u.DisplayName,
+		Bio:               u.Bio,
+		ProfilePicture:    u.ProfilePicture,
+		Website:           u.Website,
+		IsPrivate:         u.IsPrivate,
+		CreatedAt:         u.CreatedAt,
+		UsernameUpdatedAt: u.UsernameUpdatedAt,
+		IsVerified:        u.IsVerified,
+		LastSeen:          u.LastSeen,
+		Ranks:             u.Ranks,
+		PremiumExpires:    u.PremiumExpires,
+	}
+}
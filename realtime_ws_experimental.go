@@ -117,6 +117,41 @@ func (r *RealtimeClient) SubscribePostsForCreator(accessToken, creatorID string)
 	return r.conn.WriteJSON(msg)
 }
 
+// SubscribeGlobalMessages subscribes to new messages in the Global Channel.
+func (r *RealtimeClient) SubscribeGlobalMessages(accessToken string) error {
+	if r.conn == nil {
+		return fmt.Errorf("websocket not connected")
+	}
+
+	p := map[string]interface{}{
+		"config": map[string]interface{}{
+			"broadcast": map[string]interface{}{"ack": false, "self": false},
+			"presence":  map[string]interface{}{"key": ""},
+			"postgres_changes": []map[string]interface{}{
+				{
+					"event":  "INSERT",
+					"schema": "public",
+					"table":  "messages",
+				},
+			},
+			"private": false,
+		},
+		"access_token": accessToken,
+	}
+
+	msg := subscribePayload{
+		Topic:   "realtime:public:messages",
+		Event:   "phx_join",
+		Payload: p,
+		Ref:     r.nextRef(),
+		JoinRef: r.nextRef(),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.conn.WriteJSON(msg)
+}
+
 // StartHeartbeat starts sending phoenix heartbeats every interval until ctx is done.
 func (r *RealtimeClient) StartHeartbeat(ctx context.Context, interval time.Duration) error {
 	if r.conn == nil {
@@ -19,6 +19,9 @@ func (r *RealtimeClient) Close() error { return nil }
 func (r *RealtimeClient) SubscribePostsForCreator(_, _ string) error {
 	return fmt.Errorf("realtime disabled: build with -tags realtime")
 }
+func (r *RealtimeClient) SubscribeGlobalMessages(_ string) error {
+	return fmt.Errorf("realtime disabled: build with -tags realtime")
+}
 func (r *RealtimeClient) StartHeartbeat(_ context.Context, _ time.Duration) error {
 	return fmt.Errorf("realtime disabled: build with -tags realtime")
 }
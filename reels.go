@@ -0,0 +1,91 @@
+package flaro
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// GetReelsPage retrieves a page of reels, newest first. Pass the previous page's NextCursor
+// to continue; the cursor stays stable when new reels are added, unlike Offset, which is
+// only used when Cursor is empty.
+func (c *Client) GetReelsPage(accessToken string, params *ReelsPageParams) (*ReelsPage, error) {
+	if params == nil {
+		params = &ReelsPageParams{}
+	}
+	limit := params.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("select", "*")
+	queryParams.Set("order", "created_at.desc,id.desc")
+	queryParams.Set("limit", strconv.Itoa(limit))
+	if params.Cursor != "" {
+		cur, err := decodeTimelineCursor(params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		queryParams.Set("or", cur.filter())
+	} else if params.Offset > 0 {
+		queryParams.Set("offset", strconv.Itoa(params.Offset))
+	}
+	if params.CreatorID != "" {
+		queryParams.Set("creator_id", "eq."+params.CreatorID)
+	}
+	if params.Tag != "" {
+		queryParams.Set("tags", containsFilter(params.Tag))
+	}
+	if params.IsPrivate != nil {
+		queryParams.Set("is_private", "eq."+strconv.FormatBool(*params.IsPrivate))
+	}
+
+	var reels []Reel
+	endpoint := "/rest/v1/reels?" + queryParams.Encode()
+	if err := c.fetchJSON(accessToken, endpoint, "get reels", &reels); err != nil {
+		return nil, err
+	}
+
+	page := &ReelsPage{Reels: reels}
+	// A full page may have more reels behind it; a short page is the end
+	if len(reels) == limit {
+		last := reels[len(reels)-1]
+		page.NextCursor = encodeTimelineCursor(timelineCursor{CreatedAt: last.CreatedAt.Time, ID: last.ID})
+	}
+	return page, nil
+}
+
+// GetUserReels retrieves all reels created by userID, newest first
+func (c *Client) GetUserReels(accessToken, userID string) ([]Reel, error) {
+	queryParams := url.Values{}
+	queryParams.Set("select", "*")
+	queryParams.Set("creator_id", "eq."+userID)
+	queryParams.Set("order", "created_at.desc.nullslast")
+
+	var reels []Reel
+	endpoint := "/rest/v1/reels?" + queryParams.Encode()
+	if err := c.fetchJSON(accessToken, endpoint, "get user reels", &reels); err != nil {
+		return nil, err
+	}
+	return reels, nil
+}
+
+// GetReel retrieves a single reel by its ID.
+// Returns an error wrapping ErrNotFound if the reel doesn't exist or isn't visible to the user.
+func (c *Client) GetReel(accessToken, reelID string) (*Reel, error) {
+	queryParams := url.Values{}
+	queryParams.Set("select", "*")
+	queryParams.Set("id", "eq."+reelID)
+	queryParams.Set("limit", "1")
+
+	var reels []Reel
+	endpoint := "/rest/v1/reels?" + queryParams.Encode()
+	if err := c.fetchJSON(accessToken, endpoint, "get reel", &reels); err != nil {
+		return nil, err
+	}
+	if len(reels) == 0 {
+		return nil, fmt.Errorf("reel %w", ErrNotFound)
+	}
+	return &reels[0], nil
+}
@@ -0,0 +1,503 @@
+package flaro
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// tusVersion is the TUS protocol version spoken by Supabase Storage
+	tusVersion = "1.0.0"
+	// DefaultResumableChunkSize is the chunk size Supabase Storage requires for every chunk but the last
+	DefaultResumableChunkSize = 6 << 20 // 6 MiB
+	// fingerprintSampleSize is how much of the file is hashed to fingerprint an upload
+	fingerprintSampleSize = 1 << 20
+)
+
+// ResumableUploadState is the progress of a resumable upload, persisted between attempts
+type ResumableUploadState struct {
+	UploadURL  string `json:"upload_url"`
+	Bucket     string `json:"bucket"`
+	ObjectPath string `json:"object_path"`
+	Size       int64  `json:"size"`
+	Offset     int64  `json:"offset"`
+}
+
+// UploadStore persists ResumableUploadState by upload fingerprint so an interrupted
+// upload can continue after a crash or restart
+type UploadStore interface {
+	Get(fingerprint string) (*ResumableUploadState, error) // nil, nil if unknown
+	Set(fingerprint string, state ResumableUploadState) error
+	Delete(fingerprint string) error
+}
+
+// MemoryUploadStore keeps upload state in memory; uploads resume within the same process only
+type MemoryUploadStore struct {
+	mu     sync.Mutex
+	states map[string]ResumableUploadState
+}
+
+// NewMemoryUploadStore creates an empty in-memory upload store
+func NewMemoryUploadStore() *MemoryUploadStore {
+	return &MemoryUploadStore{states: make(map[string]ResumableUploadState)}
+}
+
+// Get implements UploadStore
+func (s *MemoryUploadStore) Get(fingerprint string) (*ResumableUploadState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[fingerprint]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// Set implements UploadStore
+func (s *MemoryUploadStore) Set(fingerprint string, state ResumableUploadState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[fingerprint] = state
+	return nil
+}
+
+// Delete implements UploadStore
+func (s *MemoryUploadStore) Delete(fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, fingerprint)
+	return nil
+}
+
+// FileUploadStore keeps upload state as one JSON file per fingerprint in Dir,
+// so uploads resume across process restarts
+type FileUploadStore struct {
+	Dir string
+}
+
+// Get implements UploadStore
+func (s FileUploadStore) Get(fingerprint string) (*ResumableUploadState, error) {
+	data, err := os.ReadFile(s.path(fingerprint))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload state: %w", err)
+	}
+	var state ResumableUploadState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse upload state: %w", err)
+	}
+	return &state, nil
+}
+
+// Set implements UploadStore. The file is replaced atomically so a crash never leaves a torn state.
+func (s FileUploadStore) Set(fingerprint string, state ResumableUploadState) error {
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create upload state directory: %w", err)
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal upload state: %w", err)
+	}
+	tmp := s.path(fingerprint) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write upload state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path(fingerprint)); err != nil {
+		return fmt.Errorf("failed to write upload state: %w", err)
+	}
+	return nil
+}
+
+// Delete implements UploadStore
+func (s FileUploadStore) Delete(fingerprint string) error {
+	if err := os.Remove(s.path(fingerprint)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete upload state: %w", err)
+	}
+	return nil
+}
+
+func (s FileUploadStore) path(fingerprint string) string {
+	return filepath.Join(s.Dir, fingerprint+".json")
+}
+
+// ResumableUploadOptions configures a ResumableUpload. Zero values pick the defaults.
+type ResumableUploadOptions struct {
+	Bucket       string                  // Target bucket (default reel-videos)
+	ObjectPath   string                  // Object path inside the bucket (default uploads/<uuid><ext>)
+	ContentType  string                  // MIME type (default: sniffed from the first 512 bytes)
+	CacheControl int                     // Cache lifetime in seconds (default 3600)
+	ChunkSize    int64                   // Bytes per PATCH (default DefaultResumableChunkSize)
+	Store        UploadStore             // Where progress is persisted (default: a new MemoryUploadStore)
+	Fingerprint  string                  // Stable key for Store (default: derived from Bucket, ObjectPath, size and the file's first MiB)
+	MaxRetries   int                     // Retries per chunk after network errors (default 3)
+	Progress     func(sent, total int64) // Called after every chunk
+	Upsert       bool                    // Overwrite an existing object at ObjectPath (x-upsert)
+}
+
+// ResumableUpload uploads a file with the TUS resumable protocol at /storage/v1/upload/resumable.
+// The file is sent in chunks; the upload URL and offset are saved to the UploadStore after
+// each chunk, so calling Upload again (even from a new process with the same store and
+// fingerprint) continues where it stopped.
+type ResumableUpload struct {
+	client      *Client
+	accessToken string
+	file        io.ReadSeeker
+	size        int64
+	opts        ResumableUploadOptions
+}
+
+// NewResumableUpload prepares a resumable upload of size bytes from file
+func (c *Client) NewResumableUpload(accessToken string, file io.ReadSeeker, size int64, opts ResumableUploadOptions) (*ResumableUpload, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("upload size must be positive")
+	}
+	if opts.Bucket == "" {
+		opts.Bucket = ReelVideosBucket
+	}
+	if opts.CacheControl <= 0 {
+		opts.CacheControl = 3600
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = DefaultResumableChunkSize
+	}
+	if opts.Store == nil {
+		opts.Store = NewMemoryUploadStore()
+	}
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = 3
+	}
+
+	if opts.ContentType == "" || opts.Fingerprint == "" {
+		sample := make([]byte, fingerprintSampleSize)
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to seek file: %w", err)
+		}
+		n, err := io.ReadFull(file, sample)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		sample = sample[:n]
+		if opts.ContentType == "" {
+			opts.ContentType = http.DetectContentType(sample)
+		}
+		if opts.Fingerprint == "" {
+			h := sha256.New()
+			fmt.Fprintf(h, "%s|%s|%d|", opts.Bucket, opts.ObjectPath, size)
+			h.Write(sample)
+			opts.Fingerprint = hex.EncodeToString(h.Sum(nil))
+		}
+	}
+
+	return &ResumableUpload{
+		client:      c,
+		accessToken: accessToken,
+		file:        file,
+		size:        size,
+		opts:        opts,
+	}, nil
+}
+
+// Fingerprint returns the key the upload's progress is stored under
+func (u *ResumableUpload) Fingerprint() string {
+	return u.opts.Fingerprint
+}
+
+// Upload sends the remaining chunks and returns the stored object's key once complete
+func (u *ResumableUpload) Upload(ctx context.Context) (*VideoUploadResponse, error) {
+	state, err := u.resumeOrCreate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	retries := 0
+	for state.Offset < u.size {
+		offset, err := u.sendChunk(ctx, state)
+		if err != nil {
+			if ctx.Err() != nil || retries >= u.opts.MaxRetries || !isRetryableUploadError(err) {
+				return nil, err
+			}
+			retries++
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Duration(retries) * time.Second):
+			}
+			// The server may have stored part of the chunk; ask where to continue
+			offset, err = u.fetchOffset(ctx, state.UploadURL)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			// A server that accepts a chunk without moving the offset would loop forever
+			if offset <= state.Offset || offset > u.size {
+				return nil, fmt.Errorf("upload chunk moved offset from %d to %d of %d bytes", state.Offset, offset, u.size)
+			}
+			retries = 0
+		}
+
+		state.Offset = offset
+		if err := u.opts.Store.Set(u.opts.Fingerprint, *state); err != nil {
+			return nil, err
+		}
+		if u.opts.Progress != nil {
+			u.opts.Progress(state.Offset, u.size)
+		}
+	}
+
+	if err := u.opts.Store.Delete(u.opts.Fingerprint); err != nil {
+		return nil, err
+	}
+
+	result := &VideoUploadResponse{
+		Key: state.Bucket + "/" + state.ObjectPath,
+		URL: u.client.publicObjectURL(state.Bucket, state.ObjectPath),
+	}
+	// TUS doesn't return the object ID; look it up, but the upload itself has succeeded either way
+	if id, err := u.client.objectID(ctx, u.accessToken, state.Bucket, state.ObjectPath); err == nil {
+		result.Id = id
+	}
+	return result, nil
+}
+
+// resumeOrCreate loads saved state and checks it with the server, or creates a new upload
+func (u *ResumableUpload) resumeOrCreate(ctx context.Context) (*ResumableUploadState, error) {
+	saved, err := u.opts.Store.Get(u.opts.Fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	if saved != nil && saved.Size == u.size {
+		offset, err := u.fetchOffset(ctx, saved.UploadURL)
+		if err == nil {
+			saved.Offset = offset
+			return saved, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		// The server forgot the upload (expired); start over
+	}
+
+	objectPath := u.opts.ObjectPath
+	if objectPath == "" {
+		id, err := newUUID()
+		if err != nil {
+			return nil, err
+		}
+		objectPath = "uploads/" + id + objectExtension("", u.opts.ContentType)
+	}
+	uploadURL, err := u.create(ctx, objectPath)
+	if err != nil {
+		return nil, err
+	}
+	state := &ResumableUploadState{
+		UploadURL:  uploadURL,
+		Bucket:     u.opts.Bucket,
+		ObjectPath: objectPath,
+		Size:       u.size,
+	}
+	if err := u.opts.Store.Set(u.opts.Fingerprint, *state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// create registers a new TUS upload and returns its URL
+func (u *ResumableUpload) create(ctx context.Context, objectPath string) (string, error) {
+	metadata := map[string]string{
+		"bucketName":   u.opts.Bucket,
+		"objectName":   objectPath,
+		"contentType":  u.opts.ContentType,
+		"cacheControl": strconv.Itoa(u.opts.CacheControl),
+	}
+	headers := map[string]string{
+		"Upload-Length":   strconv.FormatInt(u.size, 10),
+		"Upload-Metadata": encodeTusMetadata(metadata),
+	}
+	if u.opts.Upsert {
+		headers["x-upsert"] = "true"
+	}
+
+	resp, err := u.client.tusRequest(ctx, "POST", u.client.baseURL+"/storage/v1/upload/resumable", u.accessToken, nil, headers)
+	if err != nil {
+		return "", fmt.Errorf("failed to create resumable upload: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != 201 {
+		return "", fmt.Errorf("create resumable upload failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("create resumable upload response has no Location header")
+	}
+	// Location may be relative to the storage host
+	base, err := url.Parse(u.client.baseURL + "/storage/v1/upload/resumable")
+	if err != nil {
+		return "", fmt.Errorf("failed to parse base URL: %w", err)
+	}
+	ref, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("invalid Location header %q: %w", location, err)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// fetchOffset asks the server how many bytes of the upload it has stored.
+// Returns an error wrapping ErrNotFound if the upload no longer exists.
+func (u *ResumableUpload) fetchOffset(ctx context.Context, uploadURL string) (int64, error) {
+	resp, err := u.client.tusRequest(ctx, "HEAD", uploadURL, u.accessToken, nil, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get resumable upload offset: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 || resp.StatusCode == 410 {
+		return 0, fmt.Errorf("resumable upload %w", ErrNotFound)
+	}
+	if resp.StatusCode != 200 && resp.StatusCode != 204 {
+		return 0, fmt.Errorf("get resumable upload offset failed with status %d", resp.StatusCode)
+	}
+	return parseUploadOffset(resp.Header.Get("Upload-Offset"))
+}
+
+// sendChunk PATCHes the chunk starting at state.Offset and returns the new offset
+func (u *ResumableUpload) sendChunk(ctx context.Context, state *ResumableUploadState) (int64, error) {
+	if _, err := u.file.Seek(state.Offset, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to seek file: %w", err)
+	}
+	length := u.opts.ChunkSize
+	if remaining := u.size - state.Offset; remaining < length {
+		length = remaining
+	}
+	chunk := make([]byte, length)
+	if _, err := io.ReadFull(u.file, chunk); err != nil {
+		return 0, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	headers := map[string]string{
+		"Upload-Offset": strconv.FormatInt(state.Offset, 10),
+		"Content-Type":  "application/offset+octet-stream",
+	}
+	resp, err := u.client.tusRequest(ctx, "PATCH", state.UploadURL, u.accessToken, bytes.NewReader(chunk), headers)
+	if err != nil {
+		return 0, &retryableUploadError{err: fmt.Errorf("failed to upload chunk: %w", err)}
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != 204 {
+		err := fmt.Errorf("upload chunk failed with status %d: %s", resp.StatusCode, string(body))
+		// 409 is an offset mismatch and 5xx a server hiccup; both recover by re-reading the offset
+		if resp.StatusCode == 409 || resp.StatusCode >= 500 {
+			return 0, &retryableUploadError{err: err}
+		}
+		return 0, err
+	}
+	return parseUploadOffset(resp.Header.Get("Upload-Offset"))
+}
+
+// tusRequest sends a TUS protocol request with the standard client headers
+func (c *Client) tusRequest(ctx context.Context, method, target, accessToken string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Tus-Resumable", tusVersion)
+	req.Header.Set("apikey", c.apiKey)
+	req.Header.Set("user-agent", "Dart/3.9 (dart:io)")
+	req.Header.Set("x-client-info", "supabase-flutter/2.10.1")
+	req.Header.Set("authorization", "Bearer "+accessToken)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return c.httpClientFor(ctx).Do(req)
+}
+
+// objectID looks up the ID of a stored object
+func (c *Client) objectID(ctx context.Context, accessToken, bucket, objectPath string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/storage/v1/object/info/"+bucket+"/"+escapeObjectPath(objectPath), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("apikey", c.apiKey)
+	req.Header.Set("authorization", "Bearer "+accessToken)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get object info: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("get object info failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	var info struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return "", fmt.Errorf("failed to parse object info response: %w", err)
+	}
+	return info.ID, nil
+}
+
+// retryableUploadError marks chunk failures that can be retried after re-reading the offset
+type retryableUploadError struct {
+	err error
+}
+
+func (e *retryableUploadError) Error() string { return e.err.Error() }
+func (e *retryableUploadError) Unwrap() error { return e.err }
+
+// isRetryableUploadError reports whether err was marked as retryable
+func isRetryableUploadError(err error) bool {
+	var re *retryableUploadError
+	return errors.As(err, &re)
+}
+
+// parseUploadOffset parses a TUS Upload-Offset header
+func parseUploadOffset(header string) (int64, error) {
+	if header == "" {
+		return 0, fmt.Errorf("missing Upload-Offset header")
+	}
+	offset, err := strconv.ParseInt(header, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed Upload-Offset header %q: %w", header, err)
+	}
+	return offset, nil
+}
+
+// encodeTusMetadata renders TUS Upload-Metadata: comma separated "key base64(value)" pairs
+func encodeTusMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+" "+base64.StdEncoding.EncodeToString([]byte(metadata[k])))
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,203 @@
+package flaro
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ContentKind identifies a type of content: which kinds a search covers, or what a
+// MentionEvent refers to
+type ContentKind string
+
+const (
+	KindPost    ContentKind = "post"
+	KindReel    ContentKind = "reel"
+	KindComment ContentKind = "comment"
+)
+
+// trendingPageSize and trendingMaxPosts bound how many recent posts TrendingTags reads
+const (
+	trendingPageSize = 1000
+	trendingMaxPosts = 10000
+)
+
+// SearchByTag retrieves posts and/or reels tagged with tag, newest first. A leading "#" is
+// ignored. Stored tags can't be lowercased in a query, so only four spellings match: tag as
+// given, lowercase, uppercase and capitalised; a mixed-case tag like "GoLang" is only found
+// by that exact spelling. kinds selects the content types; nil or empty searches both. page
+// applies to each kind separately.
+func (c *Client) SearchByTag(accessToken, tag string, kinds []ContentKind, page *PageParams) (*TagSearchResult, error) {
+	tag = normalizeTag(tag)
+	if tag == "" {
+		return nil, fmt.Errorf("tag is empty")
+	}
+	if len(kinds) == 0 {
+		kinds = []ContentKind{KindPost, KindReel}
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("select", "*")
+	queryParams.Set("tags", overlapsFilter(tagSpellings(tag)...))
+	queryParams.Set("order", "created_at.desc,id.desc")
+	page.apply(queryParams)
+
+	result := &TagSearchResult{Posts: []Post{}, Reels: []Reel{}}
+	for _, kind := range kinds {
+		switch kind {
+		case KindPost:
+			endpoint := "/rest/v1/posts?" + queryParams.Encode()
+			if err := c.fetchJSON(accessToken, endpoint, "search posts by tag", &result.Posts); err != nil {
+				return nil, err
+			}
+		case KindReel:
+			endpoint := "/rest/v1/reels?" + queryParams.Encode()
+			if err := c.fetchJSON(accessToken, endpoint, "search reels by tag", &result.Reels); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unknown content kind %q", kind)
+		}
+	}
+	return result, nil
+}
+
+// SearchPosts finds posts whose content matches query, newest first. It uses PostgreSQL
+// full-text search (websearch syntax, so "quoted phrases" and -excluded words work) and
+// falls back to a case-insensitive substring match if the server can't run it.
+func (c *Client) SearchPosts(accessToken, query string, page *PageParams) ([]Post, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("search query is empty")
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("select", "*")
+	queryParams.Set("content", "wfts(simple)."+query)
+	queryParams.Set("order", "created_at.desc,id.desc")
+	page.apply(queryParams)
+
+	var posts []Post
+	err := c.fetchJSON(accessToken, "/rest/v1/posts?"+queryParams.Encode(), "search posts", &posts)
+	if err == nil {
+		return posts, nil
+	}
+	if !isFullTextUnavailable(err) {
+		return nil, err
+	}
+
+	// Fallback: % and _ in the query are escaped so they match literally; * still acts as a
+	// wildcard because PostgREST rewrites it
+	queryParams.Set("content", "ilike.%"+escapeLikePattern(query)+"%")
+	if err := c.fetchJSON(accessToken, "/rest/v1/posts?"+queryParams.Encode(), "search posts", &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// TrendingTags counts the tags of posts created within window and returns the limit most
+// used, most frequent first. Tags are compared case-insensitively and without a leading "#".
+// At most the 10000 newest posts in the window are considered.
+func (c *Client) TrendingTags(accessToken string, window time.Duration, limit int) ([]TagCount, error) {
+	if window <= 0 {
+		return nil, fmt.Errorf("trending window must be positive")
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	since := FormatTimestamp(c.now().Add(-window))
+
+	counts := make(map[string]int)
+	for offset := 0; offset < trendingMaxPosts; offset += trendingPageSize {
+		queryParams := url.Values{}
+		queryParams.Set("select", "tags")
+		queryParams.Set("created_at", "gte."+since)
+		queryParams.Set("tags", "neq.{}")
+		queryParams.Set("order", "created_at.desc,id.desc")
+		queryParams.Set("limit", strconv.Itoa(trendingPageSize))
+		queryParams.Set("offset", strconv.Itoa(offset))
+
+		var posts []Post
+		endpoint := "/rest/v1/posts?" + queryParams.Encode()
+		if err := c.fetchJSON(accessToken, endpoint, "get trending tags", &posts); err != nil {
+			return nil, err
+		}
+		for _, post := range posts {
+			// Count each tag once per post
+			seen := make(map[string]bool, len(post.Tags))
+			for _, tag := range post.Tags {
+				tag = strings.ToLower(normalizeTag(tag))
+				if tag != "" && !seen[tag] {
+					seen[tag] = true
+					counts[tag]++
+				}
+			}
+		}
+		if len(posts) < trendingPageSize {
+			break
+		}
+	}
+
+	trending := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		trending = append(trending, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(trending, func(i, j int) bool {
+		if trending[i].Count != trending[j].Count {
+			return trending[i].Count > trending[j].Count
+		}
+		return trending[i].Tag < trending[j].Tag
+	})
+	if len(trending) > limit {
+		trending = trending[:limit]
+	}
+	return trending, nil
+}
+
+// apply adds the page's limit and offset to queryParams. A nil page leaves them unset.
+func (p *PageParams) apply(queryParams url.Values) {
+	if p == nil {
+		return
+	}
+	if p.Limit > 0 {
+		queryParams.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset > 0 {
+		queryParams.Set("offset", strconv.Itoa(p.Offset))
+	}
+}
+
+// normalizeTag trims whitespace and a leading "#" from a tag
+func normalizeTag(tag string) string {
+	return strings.TrimPrefix(strings.TrimSpace(tag), "#")
+}
+
+// tagSpellings returns the common capitalisations of tag, starting with how it was given
+func tagSpellings(tag string) []string {
+	lower := strings.ToLower(tag)
+	capitalised := lower
+	if r, size := utf8.DecodeRuneInString(lower); r != utf8.RuneError {
+		capitalised = string(unicode.ToUpper(r)) + lower[size:]
+	}
+	return uniqueStrings([]string{tag, lower, strings.ToUpper(tag), capitalised})
+}
+
+// isFullTextUnavailable reports whether err means the server couldn't run a full-text
+// filter: an undefined text search function or configuration, or a column that isn't text
+func isFullTextUnavailable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.Code {
+	case "42883", "42704", "42804":
+		return true
+	}
+	return false
+}
@@ -2,15 +2,13 @@ package flaro
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"mime/multipart"
-	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
-	"time"
 )
 
 // GetPosts retrieves posts with optional pagination
@@ -105,8 +103,12 @@ func (c *Client) GetFollowing(accessToken, followerID string) ([]Follow, error)
 	return follows, nil
 }
 
-// GetUser retrieves a specific user's profile by user ID
+// GetUser retrieves a specific user's profile by user ID, using the client's ProfileCache when possible
 func (c *Client) GetUser(accessToken, userID string) (*UserProfile, error) {
+	if cached, ok := c.profiles.Get(userID); ok {
+		return cached, nil
+	}
+
 	// Build query parameters
 	queryParams := url.Values{}
 	queryParams.Set("select", "*")
@@ -143,9 +145,10 @@ func (c *Client) GetUser(accessToken, userID string) (*UserProfile, error) {
 	}
 
 	if len(userProfiles) == 0 {
-		return nil, fmt.Errorf("user not found")
+		return nil, fmt.Errorf("user %w", ErrNotFound)
 	}
 
+	c.profiles.Put(userProfiles[0])
 	return &userProfiles[0], nil
 }
 
@@ -297,44 +300,14 @@ func (c *Client) GetComments(accessToken, postID string) ([]Comment, error) {
 	return comments, nil
 }
 
-// GetCommentCount retrieves the count of comments for a specific post
+// GetCommentCount retrieves the count of comments for a specific post.
+// The count is computed server-side via CountPostComments instead of downloading every comment ID.
 func (c *Client) GetCommentCount(accessToken, postID string) (int, error) {
-	// Build query parameters
-	queryParams := url.Values{}
-	queryParams.Set("select", "id")
-	queryParams.Set("post_id", "eq."+postID)
-
-	endpoint := "/rest/v1/comments?" + queryParams.Encode()
-
-	// Make the request
-	resp, err := c.makeAuthenticatedRequest("GET", endpoint, nil, accessToken)
-	if err != nil {
-		return 0, fmt.Errorf("failed to make get comment count request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	count, err := c.CountPostComments(accessToken, postID)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read response body: %w", err)
+		return 0, fmt.Errorf("failed to get comment count: %w", err)
 	}
-
-	// Check for errors
-	if resp.StatusCode != 200 {
-		var apiErr APIError
-		if err := json.Unmarshal(body, &apiErr); err != nil {
-			return 0, fmt.Errorf("get comment count failed with status %d: %s", resp.StatusCode, string(body))
-		}
-		return 0, &apiErr
-	}
-
-	// Parse successful response
-	var commentIDs []CommentID
-	if err := json.Unmarshal(body, &commentIDs); err != nil {
-		return 0, fmt.Errorf("failed to parse comment count response: %w", err)
-	}
-
-	return len(commentIDs), nil
+	return count, nil
 }
 
 // PostComment creates a new comment on a post
@@ -417,76 +390,13 @@ func (c *Client) DeleteComment(accessToken, commentID string) error {
 	return nil
 }
 
-// UploadImage uploads an image for use in posts
+// UploadImage uploads an image for use in posts. See UploadImageFrom to stream large files.
 func (c *Client) UploadImage(accessToken string, imageData []byte, cacheControl int) (*ImageUploadResponse, error) {
-	// Generate timestamp for filename
-	timestamp := time.Now().UnixMilli()
-	filename := fmt.Sprintf("%d-0", timestamp)
-
-	// Build endpoint
-	endpoint := fmt.Sprintf("/storage/v1/object/post-images/uploads/%s", filename)
-
-	// Create multipart form data
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
-
-	// Add image data
-	part, err := writer.CreateFormFile("", "image")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create form file: %w", err)
-	}
-	_, err = part.Write(imageData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to write image data: %w", err)
-	}
-
-	// Add cache control
-	err = writer.WriteField("CacheControl", fmt.Sprintf("%d", cacheControl))
-	if err != nil {
-		return nil, fmt.Errorf("failed to write cache control: %w", err)
-	}
-
-	err = writer.Close()
-	if err != nil {
-		return nil, fmt.Errorf("failed to close writer: %w", err)
-	}
-
-	// Create request
-	req, err := http.NewRequest("POST", c.baseURL+endpoint, &buf)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("apikey", c.apiKey)
-	req.Header.Set("authorization", "Bearer "+accessToken)
-
-	// Make the request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make upload image request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	// Check for errors
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("upload image failed with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	// Parse successful response
-	var uploadResp ImageUploadResponse
-	if err := json.Unmarshal(body, &uploadResp); err != nil {
-		return nil, fmt.Errorf("failed to parse upload response: %w", err)
-	}
-
-	return &uploadResp, nil
+	return c.UploadImageFrom(context.Background(), accessToken, bytes.NewReader(imageData), UploadOptions{
+		CacheControl: cacheControl,
+		MaxSize:      int64(len(imageData)),
+		Size:         int64(len(imageData)),
+	})
 }
 
 // CreatePost creates a new post
@@ -496,7 +406,7 @@ func (c *Client) CreatePost(accessToken, userID, content string, mediaURLs []str
 		CreatorID: userID,
 		Content:   content,
 		MediaURLs: mediaURLs,
-		CreatedAt: time.Now().Format("2006-01-02T15:04:05.000000"),
+		CreatedAt: FormatTimestamp(c.now()),
 		Tags:      []string{},
 		Score:     0,
 		BoostEnds: nil,
@@ -578,6 +488,8 @@ func (c *Client) GetReels(accessToken string) ([]Reel, error) {
 }
 
 // GetReelByID retrieves a specific reel by its ID
+//
+// Deprecated: Use GetReel, which returns the reel itself and ErrNotFound when it is missing.
 func (c *Client) GetReelByID(accessToken, reelID string) ([]Reel, error) {
 	params := ReelsQueryParams{
 		Select: "*",
@@ -704,17 +616,11 @@ func (c *Client) GetSystemMessages(accessToken, _ string) ([]SystemMessage, erro
 
 	out := make([]SystemMessage, 0, len(detailed))
 	for _, d := range detailed {
-		// created_at example: 2025-09-25T17:33:37+00:00
-		t, err := time.Parse(time.RFC3339, d.CreatedAt)
-		if err != nil {
-			// try layout with explicit colon offset already covered by RFC3339; if it fails, ignore parse
-			t = time.Time{}
-		}
 		out = append(out, SystemMessage{
 			ID:        strconv.Itoa(d.ID),
 			UserID:    nil,
 			Content:   d.Content,
-			CreatedAt: t,
+			CreatedAt: d.CreatedAt.Time,
 		})
 	}
 	return out, nil
@@ -771,10 +677,11 @@ func (c *Client) SearchUsers(accessToken, username string) ([]SearchUser, error)
 		queryParts = append(queryParts, "select="+url.QueryEscape(params.Select))
 	}
 	if params.Username != "" {
-		// Build the ilike query with proper URL encoding: ilike.%username%
-		// The % characters need to be encoded as %25 in the URL
-		usernameQuery := "ilike.%25" + params.Username + "%25"
-		queryParts = append(queryParts, "username="+usernameQuery)
+		// Build the ilike query: ilike.%username%. % and _ in the input are escaped so they
+		// match literally (* stays a wildcard), and the whole value is URL-encoded (% becomes
+		// %25, , becomes %2C)
+		usernameQuery := "ilike.%" + escapeLikePattern(params.Username) + "%"
+		queryParts = append(queryParts, "username="+url.QueryEscape(usernameQuery))
 	}
 
 	var endpoint string
@@ -809,6 +716,10 @@ func (c *Client) SearchUsers(accessToken, username string) ([]SearchUser, error)
 		return nil, fmt.Errorf("failed to parse search users response: %w", err)
 	}
 
+	for _, u := range users {
+		c.profiles.Put(profileFromSearchUser(u))
+	}
+
 	return users, nil
 }
 
@@ -861,6 +772,7 @@ func (c *Client) UpdateUserDetails(accessToken, userID string, bio, username, pr
 	}
 
 	// The API returns no response body for successful updates (204 status)
+	c.profiles.Invalidate(userID)
 	return nil
 }
 
@@ -894,7 +806,7 @@ func (c *Client) DeletePost(accessToken, postID string) error {
 // ReportUser reports a user, post, or reel
 func (c *Client) ReportUser(accessToken, userID, reportedBy string, postID, reelID *string, reason string) error {
 	req := ReportRequest{
-		CreatedAt:  time.Now().Format("2006-01-02T15:04:05.000000"),
+		CreatedAt:  FormatTimestamp(c.now()),
 		UserID:     userID,
 		PostID:     postID,
 		ReelID:     reelID,
@@ -928,7 +840,9 @@ func (c *Client) ReportUser(accessToken, userID, reportedBy string, postID, reel
 	return nil
 }
 
-// MarkSystemMessageAsRead appends the caller userID to read_by for a system message
+// MarkSystemMessageAsRead appends the caller userID to read_by for a system message.
+// currentReadBy replaces the stored array, so readers added since it was fetched are lost;
+// MarkAllSystemMessagesRead fetches read_by itself and avoids this.
 func (c *Client) MarkSystemMessageAsRead(accessToken string, systemMessageID int, currentReadBy []string, userID string) error {
 	// ensure userID is included exactly once
 	exists := false
@@ -973,7 +887,7 @@ func (c *Client) ReportProblem(accessToken, userID, subject, content string) err
 		Content:   content,
 		UserID:    userID,
 		Status:    "open",
-		CreatedAt: time.Now().Format("2006-01-02T15:04:05.000000"),
+		CreatedAt: FormatTimestamp(c.now()),
 	}
 
 	endpoint := "/rest/v1/problems"
@@ -1008,7 +922,7 @@ func (c *Client) ContactSupport(accessToken, userID, subject, content string) er
 		Content:   content,
 		UserID:    userID,
 		Status:    "open",
-		CreatedAt: time.Now().Format("2006-01-02T15:04:05.000000"),
+		CreatedAt: FormatTimestamp(c.now()),
 	}
 
 	endpoint := "/rest/v1/support"
@@ -1036,9 +950,14 @@ func (c *Client) ContactSupport(accessToken, userID, subject, content string) er
 	return nil
 }
 
-// CreateUserProfile creates a profile row for a newly created account
+// CreateUserProfile creates a profile row for a newly created account.
+// The username is sent as given; call ValidateUsername first to apply stricter rules.
 func (c *Client) CreateUserProfile(accessToken, userID, username string) error {
-	now := time.Now().Format("2006-01-02T15:04:05.000000")
+	if username == "" {
+		return fmt.Errorf("username is required")
+	}
+
+	now := FormatTimestamp(c.now())
 	req := CreateUserProfileRequest{
 		UserID:            userID,
 		Username:          username,
@@ -1067,6 +986,12 @@ func (c *Client) CreateUserProfile(accessToken, userID, username string) error {
 		return fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	// 409 is a unique constraint violation; the user_id row may also already exist,
+	// but for a fresh account the username is the realistic culprit
+	if resp.StatusCode == 409 {
+		return fmt.Errorf("create user profile failed: %w", ErrUsernameTaken)
+	}
+
 	if resp.StatusCode != 201 {
 		var apiErr APIError
 		if err := json.Unmarshal(body, &apiErr); err != nil {
@@ -1078,78 +1003,13 @@ func (c *Client) CreateUserProfile(accessToken, userID, username string) error {
 	return nil
 }
 
-// UploadVideo uploads a video for use in reels
+// UploadVideo uploads a video for use in reels. See UploadVideoFrom to stream large files.
 func (c *Client) UploadVideo(accessToken string, videoData []byte, cacheControl int) (*VideoUploadResponse, error) {
-	// Generate timestamp for filename
-	timestamp := time.Now().UnixMilli()
-
-	// Create multipart form data
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
-
-	// Add video data field (empty name field as per API docs)
-	part, err := writer.CreateFormFile("", "video")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create form file: %w", err)
-	}
-
-	_, err = part.Write(videoData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to write video data: %w", err)
-	}
-
-	// Add CacheControl field
-	err = writer.WriteField("CacheControl", fmt.Sprintf("%d", cacheControl))
-	if err != nil {
-		return nil, fmt.Errorf("failed to write CacheControl field: %w", err)
-	}
-
-	err = writer.Close()
-	if err != nil {
-		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
-	}
-
-	// Create request
-	endpoint := fmt.Sprintf("/storage/v1/object/reel-videos/uploads/%d", timestamp)
-	req, err := http.NewRequest("POST", c.baseURL+endpoint, &buf)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("apikey", c.apiKey)
-	req.Header.Set("user-agent", "Dart/3.9 (dart:io)") // previously was flaro-go-sdk/1.0.0
-	req.Header.Set("x-client-info", "supabase-flutter/2.10.1")
-	req.Header.Set("authorization", "Bearer "+accessToken)
-
-	// Make request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to upload video: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	// Check for errors
-	if resp.StatusCode != 200 {
-		var apiErr APIError
-		if err := json.Unmarshal(body, &apiErr); err != nil {
-			return nil, fmt.Errorf("upload video failed with status %d: %s", resp.StatusCode, string(body))
-		}
-		return nil, fmt.Errorf("upload video failed: %s", apiErr.Message)
-	}
-
-	var uploadResp VideoUploadResponse
-	if err := json.Unmarshal(body, &uploadResp); err != nil {
-		return nil, fmt.Errorf("failed to parse upload video response: %w", err)
-	}
-
-	return &uploadResp, nil
+	return c.UploadVideoFrom(context.Background(), accessToken, bytes.NewReader(videoData), UploadOptions{
+		CacheControl: cacheControl,
+		MaxSize:      int64(len(videoData)),
+		Size:         int64(len(videoData)),
+	})
 }
 
 // CreateReel creates a new reel
@@ -1158,7 +1018,7 @@ func (c *Client) CreateReel(accessToken, userID, content, videoURL string) error
 		CreatorID: userID,
 		Content:   content,
 		Video:     videoURL,
-		CreatedAt: time.Now().Format("2006-01-02T15:04:05.000000"),
+		CreatedAt: FormatTimestamp(c.now()),
 		Tags:      []string{},
 		Score:     0,
 		BoostEnds: nil,
@@ -1195,34 +1055,51 @@ func (c *Client) CreateReel(accessToken, userID, content, videoURL string) error
 	return nil
 }
 
-// SendGlobalMessage sends a message to the Global Channel
-func (c *Client) SendGlobalMessage(accessToken, senderID, content string) error {
+// SendGlobalMessage posts content to the Global Channel as senderID and returns the stored
+// message. Empty and whitespace-only messages, and messages longer than opts.MaxLength when
+// set, are rejected with ErrInvalidMessage before anything is sent. With opts.IdempotencyKey
+// set, repeating the call with the same key (e.g. after a timeout) on the same Client within
+// 24 hours returns the message from the earlier attempt if it was stored instead of posting
+// it again; concurrent sends with one key run one after another. If the server accepts the
+// message without returning the row, the result is built from the fields sent and has no ID.
+func (c *Client) SendGlobalMessage(accessToken, senderID, content string, opts *SendGlobalMessageOptions) (*GlobalMessage, error) {
+	if opts == nil {
+		opts = &SendGlobalMessageOptions{}
+	}
+	if err := ValidateGlobalMessage(content, opts.MaxLength); err != nil {
+		return nil, err
+	}
+
+	createdAt := c.now()
+	if opts.IdempotencyKey != "" {
+		var retry bool
+		var done func()
+		createdAt, retry, done = c.globalSends.begin(senderID+"\x00"+opts.IdempotencyKey, createdAt)
+		defer done()
+		if retry {
+			existing, err := c.findGlobalMessage(accessToken, senderID, content, createdAt)
+			if err != nil {
+				return nil, err
+			}
+			if existing != nil {
+				return existing, nil
+			}
+		}
+	}
+
 	req := SendGlobalMessageRequest{
 		Content:   content,
 		SenderID:  senderID,
-		CreatedAt: time.Now().Format("2006-01-02T15:04:05.000000"),
+		CreatedAt: FormatTimestamp(createdAt),
 	}
-
-	endpoint := "/rest/v1/messages"
-	resp, err := c.makeAuthenticatedRequest("POST", endpoint, req, accessToken)
-	if err != nil {
-		return fmt.Errorf("failed to send global message: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+	var messages []GlobalMessage
+	if _, err := c.insertReturning(accessToken, "/rest/v1/messages?select=*", "send global message", req, &messages); err != nil {
+		return nil, err
 	}
-
-	if resp.StatusCode != 201 {
-		var apiErr APIError
-		if err := json.Unmarshal(body, &apiErr); err != nil {
-			return fmt.Errorf("send global message failed with status %d: %s", resp.StatusCode, string(body))
-		}
-		return fmt.Errorf("send global message failed: %s", apiErr.Message)
+	if len(messages) == 0 {
+		return &GlobalMessage{SenderID: senderID, Content: content, CreatedAt: NewTimestamp(createdAt)}, nil
 	}
-	return nil
+	return &messages[0], nil
 }
 
 // GetGlobalMessages retrieves messages from the Global Channel
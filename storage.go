@@ -0,0 +1,192 @@
+package flaro
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// storageListPageSize is how many entries Storage.List requests at a time
+const storageListPageSize = 100
+
+// Storage accesses Supabase Storage buckets on behalf of a signed-in user
+type Storage struct {
+	client      *Client
+	accessToken string
+}
+
+// Storage returns a storage client that authenticates with accessToken
+func (c *Client) Storage(accessToken string) *Storage {
+	return &Storage{client: c, accessToken: accessToken}
+}
+
+// PublicURL returns the public URL of bucket/objectPath. It doesn't check that the object exists.
+func (s *Storage) PublicURL(bucket, objectPath string) string {
+	return s.client.publicObjectURL(bucket, objectPath)
+}
+
+// CreateSignedURL returns a URL that grants access to bucket/objectPath for ttl, for objects
+// in private buckets
+func (s *Storage) CreateSignedURL(bucket, objectPath string, ttl time.Duration) (string, error) {
+	expiresIn := int(ttl / time.Second)
+	if expiresIn < 1 {
+		return "", fmt.Errorf("signed URL lifetime must be at least one second")
+	}
+
+	var signed struct {
+		SignedURL string `json:"signedURL"`
+	}
+	endpoint := "/storage/v1/object/sign/" + bucket + "/" + escapeObjectPath(objectPath)
+	body := map[string]int{"expiresIn": expiresIn}
+	if err := s.postJSON(endpoint, body, "create signed URL", &signed); err != nil {
+		return "", err
+	}
+	if signed.SignedURL == "" {
+		return "", fmt.Errorf("create signed URL returned no URL")
+	}
+	// The returned URL is relative to the storage API root
+	return s.client.baseURL + "/storage/v1" + signed.SignedURL, nil
+}
+
+// List returns the files and folders directly under prefix in bucket, sorted by name
+func (s *Storage) List(bucket, prefix string) ([]StorageObject, error) {
+	prefix = strings.Trim(prefix, "/")
+	objects := []StorageObject{}
+	for offset := 0; ; offset += storageListPageSize {
+		body := map[string]interface{}{
+			"prefix": prefix,
+			"limit":  storageListPageSize,
+			"offset": offset,
+			"sortBy": map[string]string{"column": "name", "order": "asc"},
+		}
+
+		var page []StorageObject
+		if err := s.postJSON("/storage/v1/object/list/"+bucket, body, "list objects", &page); err != nil {
+			return nil, err
+		}
+		objects = append(objects, page...)
+		if len(page) < storageListPageSize {
+			return objects, nil
+		}
+	}
+}
+
+// Download opens bucket/objectPath for reading. The caller must close the returned reader.
+// Reading the body counts against the client's request timeout unless ctx has a deadline,
+// which then replaces it, so give large files such as reel videos one.
+func (s *Storage) Download(ctx context.Context, bucket, objectPath string) (io.ReadCloser, error) {
+	endpoint := "/storage/v1/object/" + bucket + "/" + escapeObjectPath(objectPath)
+	req, err := http.NewRequestWithContext(ctx, "GET", s.client.baseURL+endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("apikey", s.client.apiKey)
+	req.Header.Set("user-agent", "Dart/3.9 (dart:io)")
+	req.Header.Set("x-client-info", "supabase-flutter/2.10.1")
+	req.Header.Set("authorization", "Bearer "+s.accessToken)
+
+	resp, err := s.client.httpClientFor(ctx).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make download request: %w", err)
+	}
+	if resp.StatusCode != 200 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, storageError("download", resp.StatusCode, body)
+	}
+	return resp.Body, nil
+}
+
+// Remove deletes objectPaths from bucket. Paths that don't exist are ignored.
+func (s *Storage) Remove(bucket string, objectPaths ...string) error {
+	if len(objectPaths) == 0 {
+		return nil
+	}
+	var removed []StorageObject
+	body := map[string][]string{"prefixes": objectPaths}
+	return s.doJSON("DELETE", "/storage/v1/object/"+bucket, body, "remove objects", &removed)
+}
+
+// Move renames fromPath to toPath within bucket
+func (s *Storage) Move(bucket, fromPath, toPath string) error {
+	var moved struct {
+		Message string `json:"message"`
+	}
+	body := map[string]string{
+		"bucketId":       bucket,
+		"sourceKey":      fromPath,
+		"destinationKey": toPath,
+	}
+	return s.postJSON("/storage/v1/object/move", body, "move object", &moved)
+}
+
+// postJSON sends a JSON body to a storage endpoint and decodes the JSON response into out
+func (s *Storage) postJSON(endpoint string, body interface{}, operation string, out interface{}) error {
+	return s.doJSON("POST", endpoint, body, operation, out)
+}
+
+func (s *Storage) doJSON(method, endpoint string, body interface{}, operation string, out interface{}) error {
+	resp, err := s.client.makeAuthenticatedRequest(method, endpoint, body, s.accessToken)
+	if err != nil {
+		return fmt.Errorf("failed to make %s request: %w", operation, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		return storageError(operation, resp.StatusCode, respBody)
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", operation, err)
+	}
+	return nil
+}
+
+// storageError converts a storage API error response into an error, wrapping ErrNotFound
+// for missing objects and buckets and ErrObjectExists for name conflicts
+func storageError(operation string, status int, body []byte) error {
+	var apiErr struct {
+		StatusCode string `json:"statusCode"`
+		Error      string `json:"error"`
+		Message    string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &apiErr); err != nil || apiErr.Message == "" {
+		return fmt.Errorf("%s failed with status %d: %s", operation, status, string(body))
+	}
+	// Storage reports missing objects as 400 or 404 depending on the version
+	if status == 404 || apiErr.StatusCode == "404" || apiErr.Error == "not_found" {
+		return fmt.Errorf("%s failed: %s: %w", operation, apiErr.Message, ErrNotFound)
+	}
+	if status == 409 || apiErr.StatusCode == "409" {
+		return fmt.Errorf("%s failed: %s: %w", operation, apiErr.Message, ErrObjectExists)
+	}
+	return fmt.Errorf("%s failed: %s", operation, apiErr.Message)
+}
+
+// splitObjectKey splits an upload response key ("bucket/path/to/object") into bucket and path
+func splitObjectKey(key string) (string, string, bool) {
+	bucket, objectPath, ok := strings.Cut(key, "/")
+	if !ok || bucket == "" || objectPath == "" {
+		return "", "", false
+	}
+	return bucket, objectPath, true
+}
+
+// escapeObjectPath escapes each segment of an object path for use in a URL
+func escapeObjectPath(objectPath string) string {
+	segments := strings.Split(objectPath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,123 @@
+package flaro
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+// maxMarkReadAttempts bounds how often marking one message as read is retried when
+// another client changes its read_by at the same time
+const maxMarkReadAttempts = 5
+
+// GetUnreadSystemMessages retrieves the system messages userID hasn't read, newest first
+func (c *Client) GetUnreadSystemMessages(accessToken, userID string) ([]SystemMessageDetail, error) {
+	queryParams := url.Values{}
+	queryParams.Set("select", "*")
+	queryParams.Set("or", unreadByFilter(userID))
+	queryParams.Set("order", "created_at.desc.nullslast")
+
+	var messages []SystemMessageDetail
+	endpoint := "/rest/v1/system_messages?" + queryParams.Encode()
+	if err := c.fetchJSON(accessToken, endpoint, "get unread system messages", &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// UnreadCount returns how many system messages userID hasn't read, without downloading them
+func (c *Client) UnreadCount(accessToken, userID string) (int, error) {
+	filters := url.Values{}
+	filters.Set("or", unreadByFilter(userID))
+	return c.Count(accessToken, "system_messages", filters, CountExact)
+}
+
+// MarkAllSystemMessagesRead adds userID to read_by of every system message they haven't
+// read and returns how many were marked. Each update only applies if read_by is unchanged
+// since it was fetched; if another client changed it meanwhile, the message is fetched
+// again and the update retried, so concurrent readers are never dropped.
+func (c *Client) MarkAllSystemMessagesRead(accessToken, userID string) (int, error) {
+	messages, err := c.GetUnreadSystemMessages(accessToken, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	marked := 0
+	for _, msg := range messages {
+		changed, err := c.markSystemMessageRead(accessToken, msg, userID)
+		if err != nil {
+			return marked, err
+		}
+		if changed {
+			marked++
+		}
+	}
+	return marked, nil
+}
+
+// markSystemMessageRead adds userID to msg's read_by with a compare-and-swap on the
+// current array. It reports false if the user was already a reader.
+func (c *Client) markSystemMessageRead(accessToken string, msg SystemMessageDetail, userID string) (bool, error) {
+	for attempt := 0; attempt < maxMarkReadAttempts; attempt++ {
+		if stringSet(msg.ReadBy)[userID] {
+			return false, nil
+		}
+
+		queryParams := url.Values{}
+		queryParams.Set("id", "eq."+strconv.Itoa(msg.ID))
+		if msg.ReadBy == nil {
+			queryParams.Set("read_by", "is.null")
+		} else {
+			queryParams.Set("read_by", "eq."+arrayLiteral(msg.ReadBy))
+		}
+		req := MarkSystemMessageReadRequest{ReadBy: append(append([]string{}, msg.ReadBy...), userID)}
+		headers := map[string]string{"Prefer": "return=representation"}
+
+		endpoint := "/rest/v1/system_messages?" + queryParams.Encode()
+		resp, err := c.makeRequestWithHeaders("PATCH", endpoint, req, accessToken, headers)
+		if err != nil {
+			return false, fmt.Errorf("failed to mark system message as read: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return false, fmt.Errorf("failed to read response body: %w", err)
+		}
+
+		if resp.StatusCode != 200 {
+			var apiErr APIError
+			if err := json.Unmarshal(body, &apiErr); err != nil {
+				return false, fmt.Errorf("mark system message as read failed with status %d: %s", resp.StatusCode, string(body))
+			}
+			return false, &apiErr
+		}
+
+		var updated []SystemMessageDetail
+		if err := json.Unmarshal(body, &updated); err != nil {
+			return false, fmt.Errorf("failed to parse mark system message as read response: %w", err)
+		}
+		if len(updated) > 0 {
+			return true, nil
+		}
+
+		// No row matched: read_by changed since it was fetched (or the message is gone)
+		var fresh []SystemMessageDetail
+		endpoint = "/rest/v1/system_messages?select=*&id=eq." + strconv.Itoa(msg.ID)
+		if err := c.fetchJSON(accessToken, endpoint, "get system message", &fresh); err != nil {
+			return false, err
+		}
+		if len(fresh) == 0 {
+			return false, nil
+		}
+		msg = fresh[0]
+	}
+	return false, fmt.Errorf("mark system message %d as read: read_by kept changing, giving up after %d attempts", msg.ID, maxMarkReadAttempts)
+}
+
+// unreadByFilter builds an "or" filter matching messages whose read_by doesn't contain
+// userID. A NULL read_by counts as unread; "not.cs" alone would leave those out.
+func unreadByFilter(userID string) string {
+	return "(read_by.is.null,read_by.not." + containsFilter(userID) + ")"
+}
@@ -0,0 +1,144 @@
+package flaro
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetHomeTimeline retrieves a page of posts from the users that userID follows, newest first.
+// Followed user IDs are queried in chunks to keep URLs short and the results are merged by
+// created_at (ties broken by post ID), so NextCursor stays stable when new posts arrive.
+func (c *Client) GetHomeTimeline(accessToken, userID string, params *HomeTimelineParams) (*HomeTimelinePage, error) {
+	if params == nil {
+		params = &HomeTimelineParams{}
+	}
+	limit := params.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	var after *timelineCursor
+	if params.Cursor != "" {
+		cur, err := decodeTimelineCursor(params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = cur
+	}
+
+	creatorIDs, err := c.getFollowingIDs(accessToken, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get followed users: %w", err)
+	}
+	if params.IncludeSelf {
+		creatorIDs = append(creatorIDs, userID)
+	}
+	creatorIDs = uniqueStrings(creatorIDs)
+	if len(creatorIDs) == 0 {
+		return &HomeTimelinePage{Posts: []Post{}}, nil
+	}
+
+	// Each chunk returns at most limit posts, so the merged top limit is exact
+	merged := make([]Post, 0, limit)
+	for _, chunk := range chunkStrings(creatorIDs, maxInFilterIDs) {
+		queryParams := url.Values{}
+		queryParams.Set("select", "*")
+		queryParams.Set("creator_id", inFilter(chunk))
+		queryParams.Set("order", "created_at.desc,id.desc")
+		queryParams.Set("limit", strconv.Itoa(limit))
+		if after != nil {
+			queryParams.Set("or", after.filter())
+		}
+
+		var posts []Post
+		endpoint := "/rest/v1/posts?" + queryParams.Encode()
+		if err := c.fetchJSON(accessToken, endpoint, "get home timeline", &posts); err != nil {
+			return nil, err
+		}
+		merged = append(merged, posts...)
+	}
+
+	sortPostsNewestFirst(merged)
+
+	page := &HomeTimelinePage{Posts: merged}
+	if len(merged) > limit {
+		page.Posts = merged[:limit]
+	}
+	// A full page may have more posts behind it; a short page is the end of the timeline
+	if len(merged) >= limit {
+		last := page.Posts[len(page.Posts)-1]
+		page.NextCursor = encodeTimelineCursor(timelineCursor{CreatedAt: last.CreatedAt.Time, ID: last.ID})
+	}
+
+	return page, nil
+}
+
+// getFollowingIDs retrieves only the IDs of the users that followerID follows
+func (c *Client) getFollowingIDs(accessToken, followerID string) ([]string, error) {
+	queryParams := url.Values{}
+	queryParams.Set("select", "following_id")
+	queryParams.Set("follower_id", "eq."+followerID)
+
+	var follows []Follow
+	endpoint := "/rest/v1/follows?" + queryParams.Encode()
+	if err := c.fetchJSON(accessToken, endpoint, "get following", &follows); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(follows))
+	for _, f := range follows {
+		ids = append(ids, f.FollowingID)
+	}
+	return ids, nil
+}
+
+// sortPostsNewestFirst orders posts by created_at descending, then by ID descending
+func sortPostsNewestFirst(posts []Post) {
+	sort.SliceStable(posts, func(i, j int) bool {
+		if !posts[i].CreatedAt.Equal(posts[j].CreatedAt.Time) {
+			return posts[i].CreatedAt.After(posts[j].CreatedAt.Time)
+		}
+		return posts[i].ID > posts[j].ID
+	})
+}
+
+// timelineCursor identifies the last post of a page
+type timelineCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// filter returns the PostgREST "or" filter selecting posts strictly older than the cursor
+func (tc *timelineCursor) filter() string {
+	ts := quoteFilterValue(FormatTimestamp(tc.CreatedAt))
+	id := quoteFilterValue(tc.ID)
+	return fmt.Sprintf("(created_at.lt.%s,and(created_at.eq.%s,id.lt.%s))", ts, ts, id)
+}
+
+// encodeTimelineCursor serialises a cursor into an opaque URL-safe string
+func encodeTimelineCursor(tc timelineCursor) string {
+	raw := FormatTimestamp(tc.CreatedAt) + "|" + tc.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeTimelineCursor parses a cursor produced by encodeTimelineCursor
+func decodeTimelineCursor(cursor string) (*timelineCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeline cursor: %w", err)
+	}
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, fmt.Errorf("invalid timeline cursor")
+	}
+	t, err := ParseTimestamp(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeline cursor time: %w", err)
+	}
+	return &timelineCursor{CreatedAt: t.Time, ID: parts[1]}, nil
+}
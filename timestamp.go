@@ -0,0 +1,102 @@
+package flaro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timestampLayouts are the Postgres timestamp renderings the API returns. Fractional seconds
+// are optional in every layout; zone-less values are interpreted as UTC.
+var timestampLayouts = []string{
+	"2006-01-02T15:04:05.999999999Z07:00", // 2025-09-25T17:33:37+00:00, 2025-09-21T12:57:47.685541Z
+	"2006-01-02T15:04:05.999999999Z07",    // 2025-09-25T17:33:37.123+00
+	"2006-01-02T15:04:05.999999999Z0700",  // 2025-09-25T17:33:37+0000
+	"2006-01-02T15:04:05.999999999",       // 2025-09-21T14:57:47.818532
+	"2006-01-02",
+}
+
+// Timestamp is a time.Time that tolerantly decodes every timestamp format the API returns
+// (with or without a zone, with or without microseconds, "T" or space separated).
+// JSON null decodes to the zero Timestamp and a zero Timestamp encodes as null.
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp wraps t as a Timestamp
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{Time: t}
+}
+
+// ParseTimestamp parses s using the formats described on Timestamp
+func ParseTimestamp(s string) (Timestamp, error) {
+	normalized := strings.TrimSpace(s)
+	// Postgres' text output separates date and time with a space
+	if len(normalized) > 10 && normalized[10] == ' ' {
+		normalized = normalized[:10] + "T" + normalized[11:]
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, normalized); err == nil {
+			return Timestamp{Time: t}, nil
+		}
+	}
+	return Timestamp{}, fmt.Errorf("unrecognised timestamp %q", s)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (ts *Timestamp) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*ts = Timestamp{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("timestamp must be a string: %w", err)
+	}
+	if s == "" {
+		*ts = Timestamp{}
+		return nil
+	}
+	parsed, err := ParseTimestamp(s)
+	if err != nil {
+		return err
+	}
+	*ts = parsed
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler using FormatTimestamp
+func (ts Timestamp) MarshalJSON() ([]byte, error) {
+	if ts.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(FormatTimestamp(ts.Time))
+}
+
+// Rank is a badge shown on a user's profile, e.g. "Beta"
+type Rank string
+
+// RankBeta marks beta testers
+const RankBeta Rank = "Beta"
+
+// HasRank reports whether the profile carries rank
+func (p *UserProfile) HasRank(rank Rank) bool {
+	for _, r := range p.Ranks {
+		if r == rank {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPremium reports whether the user's Flaro Premium is active at now
+func (p *UserProfile) IsPremium(now time.Time) bool {
+	return p.PremiumExpires != nil && !p.PremiumExpires.IsZero() && now.Before(p.PremiumExpires.Time)
+}
+
+// IsOnline reports whether the user was last seen within window of now
+func (p *UserProfile) IsOnline(now time.Time, window time.Duration) bool {
+	return !p.LastSeen.IsZero() && now.Sub(p.LastSeen.Time) <= window
+}
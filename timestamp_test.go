@@ -0,0 +1,86 @@
+package flaro
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	plus2 := time.FixedZone("", 2*60*60)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		// 2006-01-02T15:04:05.999999999Z07:00
+		{"2025-09-25T17:33:37+00:00", time.Date(2025, 9, 25, 17, 33, 37, 0, time.UTC)},
+		{"2025-09-21T12:57:47.685541Z", time.Date(2025, 9, 21, 12, 57, 47, 685541000, time.UTC)},
+		{"2025-09-21T14:57:47.685541+02:00", time.Date(2025, 9, 21, 14, 57, 47, 685541000, plus2)},
+		// 2006-01-02T15:04:05.999999999Z07
+		{"2025-09-25T17:33:37.123+00", time.Date(2025, 9, 25, 17, 33, 37, 123000000, time.UTC)},
+		{"2025-09-25T19:33:37+02", time.Date(2025, 9, 25, 19, 33, 37, 0, plus2)},
+		// 2006-01-02T15:04:05.999999999Z0700
+		{"2025-09-25T17:33:37+0000", time.Date(2025, 9, 25, 17, 33, 37, 0, time.UTC)},
+		{"2025-09-25T19:33:37.5+0200", time.Date(2025, 9, 25, 19, 33, 37, 500000000, plus2)},
+		// 2006-01-02T15:04:05.999999999, no zone means UTC
+		{"2025-09-21T14:57:47.818532", time.Date(2025, 9, 21, 14, 57, 47, 818532000, time.UTC)},
+		{"2025-09-21T14:57:47", time.Date(2025, 9, 21, 14, 57, 47, 0, time.UTC)},
+		// 2006-01-02
+		{"2025-09-21", time.Date(2025, 9, 21, 0, 0, 0, 0, time.UTC)},
+		// Postgres text output and surrounding whitespace
+		{"2025-09-21 14:57:47.818532+00", time.Date(2025, 9, 21, 14, 57, 47, 818532000, time.UTC)},
+		{" 2025-09-21T14:57:47Z\n", time.Date(2025, 9, 21, 14, 57, 47, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimestamp(tt.in)
+		if err != nil {
+			t.Errorf("%q: %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.in, got.Time, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "yesterday", "2025-13-01", "2025-09-21T25:00:00Z", "21/09/2025"} {
+		if _, err := ParseTimestamp(in); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+}
+
+func TestTimestampJSON(t *testing.T) {
+	var decoded struct {
+		A Timestamp  `json:"a"`
+		B Timestamp  `json:"b"`
+		C Timestamp  `json:"c"`
+		D *Timestamp `json:"d"`
+	}
+	data := `{"a":"2025-09-21T14:57:47.818532+02:00","b":null,"c":"","d":"2025-09-21"}`
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2025, 9, 21, 12, 57, 47, 818532000, time.UTC); !decoded.A.Equal(want) {
+		t.Errorf("a = %v, want %v", decoded.A.Time, want)
+	}
+	if !decoded.B.IsZero() || !decoded.C.IsZero() {
+		t.Errorf("null and empty should decode to zero, got %v and %v", decoded.B.Time, decoded.C.Time)
+	}
+	if decoded.D == nil || !decoded.D.Equal(time.Date(2025, 9, 21, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("d = %v", decoded.D)
+	}
+
+	for _, bad := range []string{`{"a":12}`, `{"a":"soon"}`} {
+		if err := json.Unmarshal([]byte(bad), &decoded); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+
+	encoded, err := json.Marshal([]Timestamp{decoded.A, {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `["2025-09-21T12:57:47.818532Z",null]`; string(encoded) != want {
+		t.Errorf("encoded %s, want %s", encoded, want)
+	}
+}
@@ -0,0 +1,129 @@
+package flaro
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// DefaultTranscriptTimeLayout is how WriteTranscript shows times unless told otherwise
+const DefaultTranscriptTimeLayout = "2006-01-02 15:04"
+
+// TranscriptFormat selects how WriteTranscript renders messages
+type TranscriptFormat string
+
+const (
+	TranscriptText      TranscriptFormat = "text"     // "[time] @username: content", one message per line
+	TranscriptMarkdown  TranscriptFormat = "markdown" // Bold username and time, content escaped as a paragraph
+	TranscriptJSONLines TranscriptFormat = "jsonl"    // One JSON object per message
+)
+
+// TranscriptOptions configures WriteTranscript. Zero values pick the defaults.
+type TranscriptOptions struct {
+	Format     TranscriptFormat // Default TranscriptText
+	Location   *time.Location   // Time zone for timestamps (default time.Local)
+	TimeLayout string           // time.Format layout (default DefaultTranscriptTimeLayout)
+}
+
+// transcriptLine is one message in the TranscriptJSONLines format
+type transcriptLine struct {
+	ID          int    `json:"id"`
+	SenderID    string `json:"sender_id"`
+	Username    string `json:"username,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	Content     string `json:"content"`
+	CreatedAt   string `json:"created_at"` // RFC 3339 in the chosen time zone
+	Time        string `json:"time"`       // Formatted with TimeLayout
+}
+
+// WriteTranscript renders messages to w in the order given, naming senders by "@username"
+// (or their user ID when the profile is missing) and showing times in opts.Location
+func WriteTranscript(w io.Writer, messages []GlobalMessageWithSender, opts TranscriptOptions) error {
+	if opts.Format == "" {
+		opts.Format = TranscriptText
+	}
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	if opts.TimeLayout == "" {
+		opts.TimeLayout = DefaultTranscriptTimeLayout
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+
+	for _, m := range messages {
+		createdAt := m.CreatedAt.In(opts.Location)
+		when := createdAt.Format(opts.TimeLayout)
+		name := m.SenderID
+		if m.Sender != nil && m.Sender.Username != "" {
+			name = "@" + m.Sender.Username
+		}
+
+		var err error
+		switch opts.Format {
+		case TranscriptText:
+			// Continuation lines are indented so every line still belongs to one message
+			content := strings.ReplaceAll(m.Content, "\n", "\n    ")
+			_, err = fmt.Fprintf(bw, "[%s] %s: %s\n", when, name, content)
+		case TranscriptMarkdown:
+			lines := strings.Split(m.Content, "\n")
+			for i, line := range lines {
+				lines[i] = escapeMarkdown(line)
+			}
+			_, err = fmt.Fprintf(bw, "**%s** · _%s_\n\n%s\n\n", escapeMarkdown(name), when, strings.Join(lines, "  \n"))
+		case TranscriptJSONLines:
+			line := transcriptLine{
+				ID:        m.ID,
+				SenderID:  m.SenderID,
+				Content:   m.Content,
+				CreatedAt: createdAt.Format(time.RFC3339),
+				Time:      when,
+			}
+			if m.Sender != nil {
+				line.Username = m.Sender.Username
+				line.DisplayName = m.Sender.DisplayName
+			}
+			err = enc.Encode(line)
+		default:
+			return fmt.Errorf("unknown transcript format %q", opts.Format)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write transcript: %w", err)
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return nil
+}
+
+// escapeMarkdown backslash-escapes characters that Markdown would interpret, so line
+// shows literally. Leading indentation is dropped.
+func escapeMarkdown(line string) string {
+	var b strings.Builder
+	for _, r := range line {
+		if strings.ContainsRune("\\`*_[]<>|~", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	// Leading whitespace would turn indented lines into code blocks
+	escaped := strings.TrimLeft(b.String(), " \t")
+
+	// Block syntax only matters at the start of a line: headings, quotes, lists
+	if escaped != "" && strings.ContainsRune("#>-+=", rune(escaped[0])) {
+		return "\\" + escaped
+	}
+	if digits := len(escaped) - len(strings.TrimLeft(escaped, "0123456789")); digits > 0 && digits < len(escaped) {
+		if next := escaped[digits]; next == '.' || next == ')' {
+			return escaped[:digits] + "\\" + escaped[digits:]
+		}
+	}
+	return escaped
+}
@@ -1,6 +1,10 @@
 package flaro
 
-import "time"
+import (
+	"errors"
+	"io"
+	"time"
+)
 
 // SignUpRequest represents the request body for user sign up
 type SignUpRequest struct {
@@ -92,10 +96,10 @@ type Post struct {
 	CreatorID string     `json:"creator_id"`
 	Content   string     `json:"content"`
 	MediaURLs []string   `json:"media_urls"`
-	CreatedAt time.Time  `json:"created_at"`
+	CreatedAt Timestamp  `json:"created_at"`
 	Tags      []string   `json:"tags"`
 	Score     int        `json:"score"`
-	BoostEnds *time.Time `json:"boost_ends"`
+	BoostEnds *Timestamp `json:"boost_ends"`
 	Boost     int        `json:"boost"`
 	IsPrivate bool       `json:"is_private"`
 	Location  *string    `json:"location"`
@@ -106,27 +110,35 @@ type Post struct {
 
 // UserProfile represents a user profile
 type UserProfile struct {
-	UserID            string      `json:"user_id"`
-	Username          string      `json:"username"`
-	DisplayName       string      `json:"display_name"`
-	Bio               string      `json:"bio"`
-	ProfilePicture    string      `json:"profile_picture"`
-	Website           *string     `json:"website"`
-	IsPrivate         bool        `json:"is_private"`
-	CreatedAt         string      `json:"created_at"`          // Changed to string to handle parsing
-	UsernameUpdatedAt *string     `json:"username_updated_at"` // Changed to string to handle parsing
-	IsVerified        bool        `json:"is_verified"`
-	LastSeen          string      `json:"last_seen"`       // Changed to string to handle parsing
-	Ranks             interface{} `json:"ranks"`           // Changed to interface{} to handle both null and array
-	PremiumExpires    *string     `json:"premium_expires"` // Changed to string to handle parsing
-}
-
-// Follow represents a follow relationship
+	UserID            string     `json:"user_id"`
+	Username          string     `json:"username"`
+	DisplayName       string     `json:"display_name"`
+	Bio               string     `json:"bio"`
+	ProfilePicture    string     `json:"profile_picture"`
+	Website           *string    `json:"website"`
+	IsPrivate         bool       `json:"is_private"`
+	CreatedAt         Timestamp  `json:"created_at"`
+	UsernameUpdatedAt *Timestamp `json:"username_updated_at"`
+	IsVerified        bool       `json:"is_verified"`
+	LastSeen          Timestamp  `json:"last_seen"`
+	Ranks             []Rank     `json:"ranks"` // nil when the user has no ranks
+	PremiumExpires    *Timestamp `json:"premium_expires"`
+}
+
+// Follow represents a follow relationship. Users holds the embedded profile on the other
+// side of the relationship: the followed user for GetFollowing, the follower for GetFollowers.
 type Follow struct {
-	FollowingID string      `json:"following_id"`
+	FollowerID  string      `json:"follower_id,omitempty"`
+	FollowingID string      `json:"following_id,omitempty"`
 	Users       UserProfile `json:"users"`
 }
 
+// FollowRequest represents the request body for following a user
+type FollowRequest struct {
+	FollowerID  string `json:"follower_id"`
+	FollowingID string `json:"following_id"`
+}
+
 // PostsQueryParams represents query parameters for posts endpoint
 type PostsQueryParams struct {
 	Select string
@@ -135,6 +147,19 @@ type PostsQueryParams struct {
 	Limit  int
 }
 
+// HomeTimelineParams represents paging parameters for the home timeline
+type HomeTimelineParams struct {
+	Limit       int    // Page size (default 20)
+	Cursor      string // Opaque cursor from a previous HomeTimelinePage.NextCursor; empty for the first page
+	IncludeSelf bool   // Include the user's own posts alongside followed users' posts
+}
+
+// HomeTimelinePage represents one page of the home timeline
+type HomeTimelinePage struct {
+	Posts      []Post `json:"posts"`
+	NextCursor string `json:"next_cursor"` // Empty when there are no more posts
+}
+
 // UserQueryParams represents query parameters for user endpoint
 type UserQueryParams struct {
 	Select string
@@ -150,7 +175,7 @@ type FollowsQueryParams struct {
 // Comment represents a comment on a post
 type Comment struct {
 	ID              string    `json:"id"`
-	CreatedAt       time.Time `json:"created_at"`
+	CreatedAt       Timestamp `json:"created_at"`
 	UserID          string    `json:"user_id"`
 	Content         string    `json:"content"`
 	Likes           []string  `json:"likes"`
@@ -192,6 +217,28 @@ type CommentsQueryParams struct {
 type ImageUploadResponse struct {
 	Key string `json:"Key"`
 	ID  string `json:"Id"`
+	URL string `json:"-"` // Public URL of the uploaded image, ready for CreatePost
+}
+
+// ProcessedImageUploadResponse represents the result of UploadProcessedImage
+type ProcessedImageUploadResponse struct {
+	Image     *ImageUploadResponse
+	Thumbnail *ImageUploadResponse // nil unless a thumbnail was requested
+	Width     int                  // Dimensions of the uploaded image
+	Height    int
+}
+
+// UploadOptions configures a streaming upload
+type UploadOptions struct {
+	CacheControl int                     // Cache lifetime in seconds (default 3600)
+	MaxSize      int64                   // Maximum accepted size in bytes (default DefaultMaxImageSize / DefaultMaxVideoSize)
+	ContentType  string                  // MIME type of the file; sniffed from the first 512 bytes when empty
+	Size         int64                   // Total size if known, only used for progress reporting
+	Progress     func(sent, total int64) // Called as data is sent; total is Size, or -1 if unknown
+	Naming       ObjectNaming            // How the object is named (default NamingRandom)
+	Filename     string                  // Original file name; its extension is kept in the object name
+	Upsert       bool                    // Overwrite an existing object with the same name (x-upsert)
+	Dedup        bool                    // Name the object by content hash and skip the upload if it already exists
 }
 
 // CreatePostRequest represents the request body for creating a new post
@@ -211,6 +258,42 @@ type CreatePostRequest struct {
 	Likes     []string `json:"likes"`
 }
 
+// PostDraft describes a post for ComposePost
+type PostDraft struct {
+	CreatorID    string                  // ID of the user creating the post
+	Content      string                  // Text of the post
+	Images       []io.Reader             // Images to upload, in display order
+	Tags         []string                // Tags for the post
+	Mentions     []string                // IDs of mentioned users
+	IsPrivate    bool                    // Whether the post is private
+	Location     *string                 // Location (optional)
+	ImageOptions UploadOptions           // Options for every image upload
+	Processing   *ImageProcessingOptions // Run ProcessImage on each image first (thumbnails are skipped)
+	Concurrency  int                     // Maximum parallel uploads (default DefaultUploadConcurrency)
+}
+
+// ReelDraft describes a reel for ComposeReel
+type ReelDraft struct {
+	CreatorID    string        // ID of the user creating the reel
+	Content      string        // Description of the reel
+	Video        io.Reader     // MP4, MOV or WebM video; an io.ReadSeeker avoids buffering it
+	Cover        io.Reader     // Cover image (optional)
+	Tags         []string      // Tags for the reel
+	Mentions     []string      // IDs of mentioned users
+	IsPrivate    bool          // Whether the reel is private
+	Location     *string       // Location (optional)
+	MaxDuration  time.Duration // Longest accepted video (default DefaultMaxReelDuration)
+	VideoOptions UploadOptions // Options for the video upload (MaxSize defaults to DefaultMaxVideoSize)
+	CoverOptions UploadOptions // Options for the cover upload
+}
+
+// ComposedReel is the result of ComposeReel
+type ComposedReel struct {
+	Reel
+	Video *VideoUploadResponse
+	Cover *ImageUploadResponse // nil without a cover; reels have no cover column, so store Cover.URL as needed
+}
+
 // RefreshTokenRequest represents the request body for refreshing a token
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token"`
@@ -218,20 +301,38 @@ type RefreshTokenRequest struct {
 
 // Reel represents a reel (video post)
 type Reel struct {
-	ID        string    `json:"id"`
-	CreatorID string    `json:"creator_id"`
-	Content   string    `json:"content"`
-	Video     string    `json:"video"`
-	CreatedAt time.Time `json:"created_at"`
-	Tags      []string  `json:"tags"`
-	Score     int       `json:"score"`
-	BoostEnds *string   `json:"boost_ends"`
-	Boost     int       `json:"boost"`
-	IsPrivate bool      `json:"is_private"`
-	Location  *string   `json:"location"`
-	Mentions  []string  `json:"mentions"`
-	Comments  []string  `json:"comments"`
-	Likes     []string  `json:"likes"`
+	ID        string     `json:"id"`
+	CreatorID string     `json:"creator_id"`
+	Content   string     `json:"content"`
+	Video     string     `json:"video"`
+	CreatedAt Timestamp  `json:"created_at"`
+	Tags      []string   `json:"tags"`
+	Score     int        `json:"score"`
+	BoostEnds *Timestamp `json:"boost_ends"`
+	Boost     int        `json:"boost"`
+	IsPrivate bool       `json:"is_private"`
+	Location  *string    `json:"location"`
+	Mentions  []string   `json:"mentions"`
+	Comments  []string   `json:"comments"`
+	Likes     []string   `json:"likes"`
+}
+
+// PostWithAuthor represents a post together with its creator's profile
+type PostWithAuthor struct {
+	Post
+	Author *UserProfile `json:"author"` // nil if the creator's profile no longer exists
+}
+
+// ReelWithAuthor represents a reel together with its creator's profile
+type ReelWithAuthor struct {
+	Reel
+	Author *UserProfile `json:"author"` // nil if the creator's profile no longer exists
+}
+
+// CommentWithAuthor represents a comment together with its author's profile
+type CommentWithAuthor struct {
+	Comment
+	Author *UserProfile `json:"author"` // nil if the author's profile no longer exists
 }
 
 // SystemMessage represents a system message
@@ -244,29 +345,29 @@ type SystemMessage struct {
 
 // SystemMessageDetail represents a detailed system message (title, image, read_by)
 type SystemMessageDetail struct {
-	ID        int      `json:"id"`
-	CreatedAt string   `json:"created_at"`
-	Title     string   `json:"title"`
-	Content   string   `json:"content"`
-	ReadBy    []string `json:"read_by"`
-	Image     string   `json:"image"`
+	ID        int       `json:"id"`
+	CreatedAt Timestamp `json:"created_at"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	ReadBy    []string  `json:"read_by"`
+	Image     string    `json:"image"`
 }
 
 // SearchUser represents a user found in search results
 type SearchUser struct {
-	Username          string      `json:"username"`
-	DisplayName       string      `json:"display_name"`
-	Bio               string      `json:"bio"`
-	ProfilePicture    string      `json:"profile_picture"`
-	Website           *string     `json:"website"`
-	IsPrivate         bool        `json:"is_private"`
-	CreatedAt         string      `json:"created_at"`
-	UsernameUpdatedAt *string     `json:"username_updated_at"`
-	IsVerified        bool        `json:"is_verified"`
-	LastSeen          string      `json:"last_seen"`
-	UserID            string      `json:"user_id"`
-	Ranks             interface{} `json:"ranks"`
-	PremiumExpires    *string     `json:"premium_expires"`
+	Username          string     `json:"username"`
+	DisplayName       string     `json:"display_name"`
+	Bio               string     `json:"bio"`
+	ProfilePicture    string     `json:"profile_picture"`
+	Website           *string    `json:"website"`
+	IsPrivate         bool       `json:"is_private"`
+	CreatedAt         Timestamp  `json:"created_at"`
+	UsernameUpdatedAt *Timestamp `json:"username_updated_at"`
+	IsVerified        bool       `json:"is_verified"`
+	LastSeen          Timestamp  `json:"last_seen"`
+	UserID            string     `json:"user_id"`
+	Ranks             []Rank     `json:"ranks"`
+	PremiumExpires    *Timestamp `json:"premium_expires"`
 }
 
 // ReelsQueryParams represents query parameters for reels endpoint
@@ -276,6 +377,78 @@ type ReelsQueryParams struct {
 	ID     string
 }
 
+// ReelsPageParams represents paging and filter parameters for GetReelsPage
+type ReelsPageParams struct {
+	Limit     int    // Page size (default 20)
+	Offset    int    // Reels to skip; ignored when Cursor is set
+	Cursor    string // Opaque cursor from a previous ReelsPage.NextCursor; empty for the first page
+	CreatorID string // Only reels by this user
+	Tag       string // Only reels tagged with this tag
+	IsPrivate *bool  // Only private (true) or public (false) reels; nil for both
+}
+
+// ReelsPage represents one page of reels
+type ReelsPage struct {
+	Reels      []Reel `json:"reels"`
+	NextCursor string `json:"next_cursor"` // Empty when there are no more reels
+}
+
+// PageParams represents offset paging parameters
+type PageParams struct {
+	Limit  int // Maximum number of results (0 for the server default)
+	Offset int // Results to skip
+}
+
+// TagSearchResult represents the posts and reels found by SearchByTag
+type TagSearchResult struct {
+	Posts []Post `json:"posts"`
+	Reels []Reel `json:"reels"`
+}
+
+// TagCount represents how many recent posts used a tag
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// MentionsPageParams represents paging parameters for GetMentions
+type MentionsPageParams struct {
+	Limit  int       // Page size (default 20)
+	Cursor string    // Opaque cursor from a previous MentionsPage.NextCursor; empty for the first page
+	Since  time.Time // Only mentions created after this time; zero for all
+}
+
+// MentionsPage represents one page of mentions
+type MentionsPage struct {
+	Mentions   []MentionEvent `json:"mentions"`
+	NextCursor string         `json:"next_cursor"` // Empty when there are no more mentions
+}
+
+// MentionEvent represents a post, reel or comment that mentions a user. Exactly one of
+// Post, Reel and Comment is set, according to Kind.
+type MentionEvent struct {
+	Kind      ContentKind `json:"kind"`
+	ActorID   string      `json:"actor_id"` // The user who wrote the mention
+	CreatedAt Timestamp   `json:"created_at"`
+	Post      *Post       `json:"post,omitempty"`
+	Reel      *Reel       `json:"reel,omitempty"`
+	Comment   *Comment    `json:"comment,omitempty"`
+}
+
+// Notification is one event in a user's notification feed. Which of the ID fields are set
+// depends on Kind.
+type Notification struct {
+	Kind            NotificationKind `json:"kind"`
+	ID              string           `json:"id"`                 // Stable key, e.g. "like:<post>:<user>", for deduplication
+	ActorID         string           `json:"actor_id,omitempty"` // The user who caused it; empty for system messages
+	PostID          string           `json:"post_id,omitempty"`
+	ReelID          string           `json:"reel_id,omitempty"`
+	CommentID       string           `json:"comment_id,omitempty"`
+	SystemMessageID int              `json:"system_message_id,omitempty"`
+	Text            string           `json:"text,omitempty"` // Comment content or system message title
+	CreatedAt       Timestamp        `json:"created_at"`     // When it happened; for likes and follows, when it was noticed
+}
+
 // ReelCommentsQueryParams represents query parameters for reel comments endpoint
 type ReelCommentsQueryParams struct {
 	Select string
@@ -303,6 +476,28 @@ type UpdateUserDetailsRequest struct {
 	ProfilePicture *string `json:"profile_picture,omitempty"` // Optional: update profile picture (can only update one at a time)
 }
 
+// ProfilePatch describes a profile update. Nil fields are left unchanged.
+// An empty Website clears it.
+type ProfilePatch struct {
+	Username       *string
+	DisplayName    *string
+	Bio            *string
+	ProfilePicture *string
+	Website        *string
+	IsPrivate      *bool
+}
+
+// ProfileLimits are optional client-side checks UpdateProfile applies, set with
+// Client.SetProfileLimits. The API documents no limits for these fields, so zero values
+// (the default) disable each check; pick values matching your app's edit profile screen.
+type ProfileLimits struct {
+	MaxDisplayNameLength   int           // In characters
+	MaxBioLength           int           // In characters
+	MaxWebsiteLength       int           // In bytes
+	UsernameChangeCooldown time.Duration // Minimum time between username changes
+	ValidateUsername       bool          // Apply ValidateUsername to new usernames
+}
+
 // ReportRequest represents the request body for reporting users/posts/reels
 type ReportRequest struct {
 	CreatedAt  string  `json:"created_at"`
@@ -320,7 +515,7 @@ type ProblemReportRequest struct {
 	Content   string `json:"content"`    // Problem description
 	UserID    string `json:"user_id"`    // User ID reporting the problem
 	Status    string `json:"status"`     // Problem status
-	CreatedAt string `json:"created_at"` // Current time in ISO 8601 format
+	CreatedAt string `json:"created_at"` // Current UTC time, see FormatTimestamp
 }
 
 // SupportRequest represents the request body for contacting support
@@ -329,7 +524,7 @@ type SupportRequest struct {
 	Content   string `json:"content"`    // Support description
 	UserID    string `json:"user_id"`    // User ID contacting support
 	Status    string `json:"status"`     // Support status
-	CreatedAt string `json:"created_at"` // Current time in ISO 8601 format
+	CreatedAt string `json:"created_at"` // Current UTC time, see FormatTimestamp
 }
 
 // MarkSystemMessageReadRequest represents the request to mark a system message as read
@@ -364,6 +559,25 @@ type ChangePasswordResponse struct {
 type VideoUploadResponse struct {
 	Key string `json:"Key"` // The key/path of the uploaded video
 	Id  string `json:"Id"`  // The ID of the uploaded video
+	URL string `json:"-"`   // Public URL of the uploaded video, ready for CreateReel
+}
+
+// StorageObject represents an entry returned by Storage.List. Folders have an empty ID.
+type StorageObject struct {
+	Name           string                 `json:"name"`
+	ID             string                 `json:"id"`
+	UpdatedAt      Timestamp              `json:"updated_at"`
+	CreatedAt      Timestamp              `json:"created_at"`
+	LastAccessedAt Timestamp              `json:"last_accessed_at"`
+	Metadata       *StorageObjectMetadata `json:"metadata"`
+}
+
+// StorageObjectMetadata holds the file details of a StorageObject
+type StorageObjectMetadata struct {
+	Size         int64  `json:"size"`
+	MimeType     string `json:"mimetype"`
+	CacheControl string `json:"cacheControl"`
+	ETag         string `json:"eTag"`
 }
 
 // CreateReelRequest represents the request body for creating a reel
@@ -371,7 +585,7 @@ type CreateReelRequest struct {
 	CreatorID string   `json:"creator_id"` // Creator ID is the current user ID
 	Content   string   `json:"content"`    // Description of the reel
 	Video     string   `json:"video"`      // Video URL from upload
-	CreatedAt string   `json:"created_at"` // Current date in ISO 8601 format
+	CreatedAt string   `json:"created_at"` // Current UTC time, see FormatTimestamp
 	Tags      []string `json:"tags"`       // Tags for the reel
 	Score     int      `json:"score"`      // Score (typically 0)
 	BoostEnds *string  `json:"boost_ends"` // Boost end time (typically null)
@@ -385,10 +599,16 @@ type CreateReelRequest struct {
 
 // GlobalMessage represents a message in the Global Channel
 type GlobalMessage struct {
-	ID        int    `json:"id"`
-	SenderID  string `json:"sender_id"`
-	Content   string `json:"content"`
-	CreatedAt string `json:"created_at"`
+	ID        int       `json:"id"`
+	SenderID  string    `json:"sender_id"`
+	Content   string    `json:"content"`
+	CreatedAt Timestamp `json:"created_at"`
+}
+
+// GlobalMessageWithSender represents a Global Channel message together with its sender's profile
+type GlobalMessageWithSender struct {
+	GlobalMessage
+	Sender *UserProfile `json:"sender"` // nil if the sender's profile no longer exists
 }
 
 // SendGlobalMessageRequest represents the request body to send a global message
@@ -398,6 +618,18 @@ type SendGlobalMessageRequest struct {
 	CreatedAt string `json:"created_at"`
 }
 
+// SendGlobalMessageOptions configures SendGlobalMessage
+type SendGlobalMessageOptions struct {
+	// IdempotencyKey identifies one logical send, e.g. a UUID made when the user pressed
+	// send. A retry with the same key on the same Client within 24 hours finds the earlier
+	// message instead of posting it again. Keys are kept in memory only, so a new Client or
+	// process can still post a duplicate.
+	IdempotencyKey string
+	// MaxLength rejects longer content, in characters, before sending. 0 means no limit;
+	// the API documents none.
+	MaxLength int
+}
+
 // APIError represents an API error response
 type APIError struct {
 	Message string `json:"message"`
@@ -408,6 +640,29 @@ func (e *APIError) Error() string {
 	return e.Message
 }
 
+var (
+	// ErrNotFound is returned (wrapped) when a requested row does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidUsername is returned (wrapped) when a username breaks the app's username rules
+	ErrInvalidUsername = errors.New("invalid username")
+	// ErrUsernameTaken is returned (wrapped) when a username is already used by another account
+	ErrUsernameTaken = errors.New("username is already taken")
+	// ErrUsernameCooldown is returned (wrapped) when the username was changed too recently
+	ErrUsernameCooldown = errors.New("username was changed too recently")
+	// ErrInvalidProfile is returned (wrapped) when a profile field fails client-side validation
+	ErrInvalidProfile = errors.New("invalid profile")
+	// ErrUploadTooLarge is returned (wrapped) when an upload exceeds its maximum size
+	ErrUploadTooLarge = errors.New("upload exceeds maximum size")
+	// ErrObjectExists is returned (wrapped) when an upload's object name is already taken
+	ErrObjectExists = errors.New("object already exists")
+	// ErrInvalidImage is returned (wrapped) when ProcessImage can't read or accept an image
+	ErrInvalidImage = errors.New("invalid image")
+	// ErrInvalidVideo is returned (wrapped) when a video fails ComposeReel's checks
+	ErrInvalidVideo = errors.New("invalid video")
+	// ErrInvalidMessage is returned (wrapped) when a Global Channel message fails client-side validation
+	ErrInvalidMessage = errors.New("invalid message")
+)
+
 // CreateUserProfileRequest represents the request body for creating a user profile
 type CreateUserProfileRequest struct {
 	UserID            string  `json:"user_id"`
@@ -0,0 +1,264 @@
+package flaro
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// Storage buckets used by the app
+const (
+	PostImagesBucket      = "post-images"
+	ReelVideosBucket      = "reel-videos"
+	ProfilePicturesBucket = "profile-pictures"
+)
+
+// Default upload size limits, applied when UploadOptions.MaxSize is zero
+const (
+	DefaultMaxImageSize = 10 << 20  // 10 MiB
+	DefaultMaxVideoSize = 100 << 20 // 100 MiB
+)
+
+// UploadImageFrom streams an image from r to the post-images bucket without buffering it
+// in memory (unless opts.Dedup or NamingContentHash needs to hash a non-seekable reader).
+// The upload is bounded by ctx; when ctx has a deadline it replaces the client's default
+// request timeout.
+func (c *Client) UploadImageFrom(ctx context.Context, accessToken string, r io.Reader, opts UploadOptions) (*ImageUploadResponse, error) {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultMaxImageSize
+	}
+	timestampName := fmt.Sprintf("%d-0", c.now().UnixMilli())
+	uploadResp, err := c.uploadToBucket(ctx, accessToken, PostImagesBucket, timestampName, r, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload image: %w", err)
+	}
+	return uploadResp, nil
+}
+
+// UploadVideoFrom streams a video from r to the reel-videos bucket without buffering it
+// in memory (unless opts.Dedup or NamingContentHash needs to hash a non-seekable reader).
+// The upload is bounded by ctx; when ctx has a deadline it replaces the client's default
+// request timeout.
+func (c *Client) UploadVideoFrom(ctx context.Context, accessToken string, r io.Reader, opts UploadOptions) (*VideoUploadResponse, error) {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultMaxVideoSize
+	}
+	timestampName := fmt.Sprintf("%d", c.now().UnixMilli())
+	uploadResp, err := c.uploadToBucket(ctx, accessToken, ReelVideosBucket, timestampName, r, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload video: %w", err)
+	}
+	return &VideoUploadResponse{Key: uploadResp.Key, Id: uploadResp.ID, URL: uploadResp.URL}, nil
+}
+
+// uploadToBucket names the upload according to opts and uploads it to bucket. In dedup mode
+// an object that already exists under the content hash is returned without uploading again.
+func (c *Client) uploadToBucket(ctx context.Context, accessToken, bucket, timestampName string, r io.Reader, opts UploadOptions) (*ImageUploadResponse, error) {
+	objectPath, r, err := c.uploadObjectPath(r, &opts, timestampName)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := func() (*ImageUploadResponse, error) {
+		id, err := c.objectID(ctx, accessToken, bucket, objectPath)
+		if err != nil {
+			return nil, err
+		}
+		return &ImageUploadResponse{Key: bucket + "/" + objectPath, ID: id, URL: c.publicObjectURL(bucket, objectPath)}, nil
+	}
+
+	if opts.Dedup && !opts.Upsert {
+		// Any lookup error just means the file is uploaded; real problems surface there
+		if uploadResp, err := existing(); err == nil {
+			return uploadResp, nil
+		}
+	}
+
+	uploadResp, err := c.uploadStream(ctx, accessToken, bucket, objectPath, r, opts)
+	if opts.Dedup && errors.Is(err, ErrObjectExists) {
+		// Someone uploaded the same file since the lookup above
+		return existing()
+	}
+	return uploadResp, err
+}
+
+// uploadStream uploads r to bucket/objectPath as a multipart form, the same way the app does,
+// piping the body so the file is never held in memory as a whole. opts.ContentType must be set.
+func (c *Client) uploadStream(ctx context.Context, accessToken, bucket, objectPath string, r io.Reader, opts UploadOptions) (*ImageUploadResponse, error) {
+	if opts.CacheControl <= 0 {
+		opts.CacheControl = 3600
+	}
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	writeErr := make(chan error, 1)
+	go func() {
+		err := writeUploadForm(writer, r, objectPath, opts.ContentType, opts)
+		pw.CloseWithError(err)
+		writeErr <- err
+	}()
+
+	// Create request
+	endpoint := "/storage/v1/object/" + bucket + "/" + escapeObjectPath(objectPath)
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+endpoint, pr)
+	if err != nil {
+		pr.Close()
+		<-writeErr
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("apikey", c.apiKey)
+	req.Header.Set("user-agent", "Dart/3.9 (dart:io)")
+	req.Header.Set("x-client-info", "supabase-flutter/2.10.1")
+	req.Header.Set("authorization", "Bearer "+accessToken)
+	if opts.Upsert {
+		req.Header.Set("x-upsert", "true")
+	}
+
+	resp, err := c.httpClientFor(ctx).Do(req)
+	// Unblock the writer if the request ended early, then surface its error first:
+	// an oversized file is more useful to report than the aborted request it caused
+	pr.Close()
+	if werr := <-writeErr; werr != nil && !errors.Is(werr, io.ErrClosedPipe) {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return nil, werr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to make upload request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Check for errors
+	if resp.StatusCode != 200 {
+		return nil, storageError("upload", resp.StatusCode, body)
+	}
+
+	var uploadResp ImageUploadResponse
+	if err := json.Unmarshal(body, &uploadResp); err != nil {
+		return nil, fmt.Errorf("failed to parse upload response: %w", err)
+	}
+	uploadResp.URL = c.publicObjectURL(bucket, objectPath)
+
+	return &uploadResp, nil
+}
+
+// quoteEscaper escapes a value for a quoted Content-Disposition parameter
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// writeUploadForm writes the file part and CacheControl field, enforcing opts.MaxSize
+// and reporting progress as the file is copied
+func writeUploadForm(writer *multipart.Writer, r io.Reader, objectPath, contentType string, opts UploadOptions) error {
+	// CreateFormFile would force application/octet-stream, so the part header is built by hand
+	partHeader := make(textproto.MIMEHeader)
+	filename := objectPath[strings.LastIndex(objectPath, "/")+1:]
+	partHeader.Set("Content-Disposition", `form-data; name="file"; filename="`+quoteEscaper.Replace(filename)+`"`)
+	partHeader.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(partHeader)
+	if err != nil {
+		return fmt.Errorf("failed to create form file: %w", err)
+	}
+
+	total := opts.Size
+	if total <= 0 {
+		total = -1
+	}
+	src := &progressReader{r: r, total: total, progress: opts.Progress}
+	// Read one byte past the limit so an oversized file is detected rather than silently truncated
+	var limited io.Reader = src
+	if opts.MaxSize > 0 {
+		limited = io.LimitReader(src, opts.MaxSize+1)
+	}
+	n, err := io.Copy(part, limited)
+	if err != nil {
+		return fmt.Errorf("failed to write file data: %w", err)
+	}
+	if opts.MaxSize > 0 && n > opts.MaxSize {
+		return fmt.Errorf("%w of %d bytes", ErrUploadTooLarge, opts.MaxSize)
+	}
+
+	// Add cache control
+	if err := writer.WriteField("CacheControl", fmt.Sprintf("%d", opts.CacheControl)); err != nil {
+		return fmt.Errorf("failed to write cache control: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close writer: %w", err)
+	}
+	return nil
+}
+
+// progressReader reports the running byte count of reads from r
+type progressReader struct {
+	r        io.Reader
+	sent     int64
+	total    int64
+	progress func(sent, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		if p.progress != nil {
+			p.progress(p.sent, p.total)
+		}
+	}
+	return n, err
+}
+
+// deleteObject removes bucket/objectPath from storage
+func (c *Client) deleteObject(accessToken, bucket, objectPath string) error {
+	endpoint := "/storage/v1/object/" + bucket + "/" + escapeObjectPath(objectPath)
+	resp, err := c.makeAuthenticatedRequest("DELETE", endpoint, nil, accessToken)
+	if err != nil {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != 200 && resp.StatusCode != 204 {
+		return fmt.Errorf("delete object failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// publicObjectURL builds the public URL for bucket/objectPath
+func (c *Client) publicObjectURL(bucket, objectPath string) string {
+	return c.baseURL + "/storage/v1/object/public/" + bucket + "/" + escapeObjectPath(objectPath)
+}
+
+// detectImageType sniffs data and returns its MIME type and usual file extension,
+// or an error if it is not a JPEG, PNG, GIF or WebP image
+func detectImageType(data []byte) (string, string, error) {
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg":
+		return contentType, ".jpg", nil
+	case "image/png":
+		return contentType, ".png", nil
+	case "image/gif":
+		return contentType, ".gif", nil
+	case "image/webp":
+		return contentType, ".webp", nil
+	}
+	return "", "", fmt.Errorf("unsupported image type %q", contentType)
+}
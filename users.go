@@ -0,0 +1,148 @@
+package flaro
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Limits checked by ValidateUsername. The API documentation doesn't define username rules
+// and the server accepts usernames outside them, so they are only applied on request.
+const (
+	// MinUsernameLength is the shortest username ValidateUsername accepts
+	MinUsernameLength = 3
+	// MaxUsernameLength is the longest username ValidateUsername accepts
+	MaxUsernameLength = 30
+)
+
+// GetUsers retrieves the profiles for ids, in the order of ids with duplicates removed.
+// Profiles present in the client's ProfileCache are served from it; the rest are fetched
+// with chunked "user_id=in.(...)" queries and cached. Unknown IDs are omitted from the result.
+func (c *Client) GetUsers(accessToken string, ids []string) ([]UserProfile, error) {
+	ids = uniqueStrings(ids)
+
+	found := make(map[string]UserProfile, len(ids))
+	missing := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if cached, ok := c.profiles.Get(id); ok {
+			found[id] = *cached
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	for _, chunk := range chunkStrings(missing, maxInFilterIDs) {
+		queryParams := url.Values{}
+		queryParams.Set("select", "*")
+		queryParams.Set("user_id", inFilter(chunk))
+
+		var users []UserProfile
+		endpoint := "/rest/v1/users?" + queryParams.Encode()
+		if err := c.fetchJSON(accessToken, endpoint, "get users", &users); err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			c.profiles.Put(u)
+			found[u.UserID] = u
+		}
+	}
+
+	out := make([]UserProfile, 0, len(found))
+	for _, id := range ids {
+		if u, ok := found[id]; ok {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+// GetUserByUsername retrieves a user's profile by exact username, ignoring case.
+// Returns an error wrapping ErrNotFound if no user has that username, or ErrInvalidUsername
+// if it contains "*", which PostgREST can't match exactly.
+func (c *Client) GetUserByUsername(accessToken, username string) (*UserProfile, error) {
+	if err := checkUsernameLookup(username); err != nil {
+		return nil, err
+	}
+
+	// LIKE wildcards are escaped (and * rejected above) so ilike acts as a case-insensitive
+	// equality check
+	queryParams := url.Values{}
+	queryParams.Set("select", "*")
+	queryParams.Set("username", "ilike."+escapeLikePattern(username))
+	queryParams.Set("limit", "1")
+
+	var users []UserProfile
+	endpoint := "/rest/v1/users?" + queryParams.Encode()
+	if err := c.fetchJSON(accessToken, endpoint, "get user by username", &users); err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("user %w", ErrNotFound)
+	}
+
+	c.profiles.Put(users[0])
+	return &users[0], nil
+}
+
+// IsUsernameAvailable reports whether username is not used by any account (compared
+// case-insensitively). It doesn't apply ValidateUsername, but a username containing "*"
+// can't be checked and returns an error wrapping ErrInvalidUsername.
+func (c *Client) IsUsernameAvailable(accessToken, username string) (bool, error) {
+	if err := checkUsernameLookup(username); err != nil {
+		return false, err
+	}
+
+	filters := url.Values{}
+	filters.Set("username", "ilike."+escapeLikePattern(username))
+
+	count, err := c.Count(accessToken, "users", filters, CountExact)
+	if err != nil {
+		return false, fmt.Errorf("failed to check username availability: %w", err)
+	}
+	return count == 0, nil
+}
+
+// ValidateUsername checks a username against conservative, opt-in rules: 3-30 characters
+// of ASCII letters, digits, underscores and periods, not starting or ending with a period
+// and without consecutive periods. These are not documented server rules; no SDK method
+// enforces them, so call this yourself, e.g. in a sign-up form. The returned error wraps
+// ErrInvalidUsername.
+func ValidateUsername(username string) error {
+	if len(username) < MinUsernameLength || len(username) > MaxUsernameLength {
+		return fmt.Errorf("%w: must be between %d and %d characters", ErrInvalidUsername, MinUsernameLength, MaxUsernameLength)
+	}
+	for _, r := range username {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.':
+		default:
+			return fmt.Errorf("%w: character %q is not allowed", ErrInvalidUsername, r)
+		}
+	}
+	if strings.HasPrefix(username, ".") || strings.HasSuffix(username, ".") {
+		return fmt.Errorf("%w: cannot start or end with a period", ErrInvalidUsername)
+	}
+	if strings.Contains(username, "..") {
+		return fmt.Errorf("%w: cannot contain consecutive periods", ErrInvalidUsername)
+	}
+	return nil
+}
+
+// checkUsernameLookup rejects usernames that an ilike filter can't match exactly
+func checkUsernameLookup(username string) error {
+	if username == "" {
+		return fmt.Errorf("username is required")
+	}
+	if strings.Contains(username, "*") {
+		return fmt.Errorf("%w: \"*\" can't be looked up exactly", ErrInvalidUsername)
+	}
+	return nil
+}
+
+// escapeLikePattern escapes the LIKE wildcards % and _ and the escape character itself so
+// they match literally in like/ilike filters. PostgREST turns every * into % before the
+// pattern reaches Postgres, escaped or not, so * stays a wildcard; exact lookups must
+// reject it or check the rows they get back.
+func escapeLikePattern(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
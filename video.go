@@ -0,0 +1,175 @@
+package flaro
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// DefaultMaxReelDuration is the longest video ComposeReel accepts by default
+const DefaultMaxReelDuration = 3 * time.Minute
+
+// VideoInfo describes a video file, as read by InspectVideo
+type VideoInfo struct {
+	ContentType string        // video/mp4, video/quicktime or video/webm
+	Extension   string        // .mp4, .mov or .webm
+	Size        int64         // File size in bytes
+	Duration    time.Duration // From the MP4/MOV movie header; zero for WebM or when unknown
+}
+
+// InspectVideo identifies an MP4, MOV or WebM file from its header and, for MP4 and MOV,
+// reads the duration from the movie header (moov/mvhd) wherever it is in the file.
+// Other formats, and MP4/MOV files whose boxes are truncated or malformed, fail with
+// ErrInvalidVideo. r is left positioned at the start of the file.
+func InspectVideo(r io.ReadSeeker) (*VideoInfo, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seek video: %w", err)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek video: %w", err)
+	}
+
+	head := make([]byte, 64)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read video: %w", err)
+	}
+	contentType, ext, err := detectVideoType(head[:n])
+	if err != nil {
+		return nil, err
+	}
+
+	info := &VideoInfo{ContentType: contentType, Extension: ext, Size: size}
+	if contentType != "video/webm" {
+		if info.Duration, err = mp4Duration(r, size); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek video: %w", err)
+	}
+	return info, nil
+}
+
+// detectVideoType sniffs the container format from the first bytes of a file
+func detectVideoType(head []byte) (string, string, error) {
+	if len(head) >= 12 && string(head[4:8]) == "ftyp" {
+		if string(head[8:12]) == "qt  " {
+			return "video/quicktime", ".mov", nil
+		}
+		return "video/mp4", ".mp4", nil
+	}
+	// Older QuickTime files start with other atoms instead of ftyp
+	if len(head) >= 8 {
+		switch string(head[4:8]) {
+		case "moov", "mdat", "wide", "free", "skip":
+			return "video/quicktime", ".mov", nil
+		}
+	}
+	// EBML header; the doc type tells WebM apart from other Matroska files
+	if len(head) >= 4 && bytes.Equal(head[:4], []byte{0x1A, 0x45, 0xDF, 0xA3}) {
+		if bytes.Contains(head, []byte("webm")) {
+			return "video/webm", ".webm", nil
+		}
+		return "", "", fmt.Errorf("%w: Matroska files other than WebM are not supported", ErrInvalidVideo)
+	}
+	return "", "", fmt.Errorf("%w: not an MP4, MOV or WebM file", ErrInvalidVideo)
+}
+
+// mp4Duration reads the duration from the moov/mvhd box of an MP4 or MOV file of size bytes
+func mp4Duration(r io.ReadSeeker, size int64) (time.Duration, error) {
+	moovStart, moovEnd, err := findMP4Box(r, 0, size, "moov")
+	if err != nil {
+		return 0, err
+	}
+	mvhdStart, mvhdEnd, err := findMP4Box(r, moovStart, moovEnd, "mvhd")
+	if err != nil {
+		return 0, err
+	}
+
+	// version(1) flags(3), then 32-bit (version 0) or 64-bit (version 1) times
+	header := make([]byte, mvhdEnd-mvhdStart)
+	if len(header) > 32 {
+		header = header[:32]
+	}
+	if _, err := r.Seek(mvhdStart, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to seek video: %w", err)
+	}
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, fmt.Errorf("failed to read video: %w", err)
+	}
+
+	var timescale, duration uint64
+	switch {
+	case len(header) >= 20 && header[0] == 0:
+		timescale = uint64(binary.BigEndian.Uint32(header[12:]))
+		duration = uint64(binary.BigEndian.Uint32(header[16:]))
+		if duration == math.MaxUint32 {
+			return 0, nil // unknown
+		}
+	case len(header) >= 32 && header[0] == 1:
+		timescale = uint64(binary.BigEndian.Uint32(header[20:]))
+		duration = binary.BigEndian.Uint64(header[24:])
+		if duration == math.MaxUint64 {
+			return 0, nil // unknown
+		}
+	default:
+		return 0, fmt.Errorf("%w: malformed mvhd box", ErrInvalidVideo)
+	}
+	if timescale == 0 {
+		return 0, fmt.Errorf("%w: mvhd box has no timescale", ErrInvalidVideo)
+	}
+
+	seconds := duration / timescale
+	if seconds > uint64(math.MaxInt64/int64(time.Second)) {
+		return 0, fmt.Errorf("%w: implausible duration", ErrInvalidVideo)
+	}
+	fraction := time.Duration(duration%timescale) * time.Second / time.Duration(timescale)
+	return time.Duration(seconds)*time.Second + fraction, nil
+}
+
+// findMP4Box scans the boxes between start and end for one of type boxType and returns the
+// bounds of its payload
+func findMP4Box(r io.ReadSeeker, start, end int64, boxType string) (int64, int64, error) {
+	var header [16]byte
+	for offset := start; offset+8 <= end; {
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return 0, 0, fmt.Errorf("failed to seek video: %w", err)
+		}
+		if _, err := io.ReadFull(r, header[:8]); err != nil {
+			return 0, 0, fmt.Errorf("failed to read video: %w", err)
+		}
+		size := int64(binary.BigEndian.Uint32(header[:4]))
+		typ := string(header[4:8])
+		headerLen := int64(8)
+		switch size {
+		case 0: // box extends to the end of its container
+			size = end - offset
+		case 1: // 64-bit size follows the type
+			if _, err := io.ReadFull(r, header[8:16]); err != nil {
+				return 0, 0, fmt.Errorf("failed to read video: %w", err)
+			}
+			large := binary.BigEndian.Uint64(header[8:16])
+			if large > math.MaxInt64 {
+				return 0, 0, fmt.Errorf("%w: malformed %q box", ErrInvalidVideo, typ)
+			}
+			size = int64(large)
+			headerLen = 16
+		}
+		// A box running past its container means the file is truncated or corrupt
+		if size < headerLen || size > end-offset {
+			return 0, 0, fmt.Errorf("%w: malformed %q box", ErrInvalidVideo, typ)
+		}
+		if typ == boxType {
+			return offset + headerLen, offset + size, nil
+		}
+		offset += size
+	}
+	return 0, 0, fmt.Errorf("%w: no %s box", ErrInvalidVideo, boxType)
+}
@@ -0,0 +1,126 @@
+package flaro
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// concat joins byte slices into a new one
+func concat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+// box builds an MP4 box with a 32-bit size
+func box(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	out := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	return append(append(out, typ...), body...)
+}
+
+// largeBox builds an MP4 box with size 1 and a 64-bit size after the type
+func largeBox(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	out := binary.BigEndian.AppendUint32(nil, 1)
+	out = append(out, typ...)
+	out = binary.BigEndian.AppendUint64(out, uint64(16+len(body)))
+	return append(out, body...)
+}
+
+// openBox builds an MP4 box with size 0, which runs to the end of its container
+func openBox(typ string, payload ...[]byte) []byte {
+	return append(append([]byte{0, 0, 0, 0}, typ...), bytes.Join(payload, nil)...)
+}
+
+// mvhd builds an mvhd payload of the given version
+func mvhd(version byte, timescale uint32, duration uint64) []byte {
+	out := []byte{version, 0, 0, 0}
+	if version == 0 {
+		out = append(out, make([]byte, 8)...) // creation and modification time
+		out = binary.BigEndian.AppendUint32(out, timescale)
+		return binary.BigEndian.AppendUint32(out, uint32(duration))
+	}
+	out = append(out, make([]byte, 16)...)
+	out = binary.BigEndian.AppendUint32(out, timescale)
+	return binary.BigEndian.AppendUint64(out, duration)
+}
+
+func TestFindMP4Box(t *testing.T) {
+	payload := []byte("payload")
+	tests := []struct {
+		name       string
+		data       []byte
+		start, end int64 // payload bounds; both 0 when an error is expected
+	}{
+		{"32-bit", concat(box("ftyp", []byte("isom")), box("moov", payload)), 20, 27},
+		{"64-bit", concat(box("ftyp", []byte("isom")), largeBox("moov", payload)), 28, 35},
+		{"size 0", concat(box("ftyp", []byte("isom")), openBox("moov", payload)), 20, 27},
+		{"missing", box("ftyp", []byte("isom")), 0, 0},
+		{"past end", append(box("ftyp"), 0, 0, 1, 0, 'm', 'o', 'o', 'v'), 0, 0},
+		{"smaller than header", append(box("ftyp"), 0, 0, 0, 4, 'm', 'o', 'o', 'v'), 0, 0},
+		{"64-bit smaller than header", append(box("ftyp"), 0, 0, 0, 1, 'm', 'o', 'o', 'v', 0, 0, 0, 0, 0, 0, 0, 8), 0, 0},
+		{"empty", nil, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := findMP4Box(bytes.NewReader(tt.data), 0, int64(len(tt.data)), "moov")
+			if tt.end == 0 {
+				if !errors.Is(err, ErrInvalidVideo) {
+					t.Fatalf("err = %v, want ErrInvalidVideo", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if start != tt.start || end != tt.end {
+				t.Fatalf("bounds = %d-%d, want %d-%d", start, end, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestMP4Duration(t *testing.T) {
+	ftyp := box("ftyp", []byte("isom"))
+	tests := []struct {
+		name    string
+		data    []byte
+		want    time.Duration
+		wantErr bool
+	}{
+		{"version 0", concat(ftyp, box("moov", box("mvhd", mvhd(0, 1000, 2500)))), 2500 * time.Millisecond, false},
+		{"version 1", concat(ftyp, box("moov", box("mvhd", mvhd(1, 90000, 90000*3600)))), time.Hour, false},
+		{"fraction", concat(ftyp, box("moov", box("mvhd", mvhd(0, 3, 4)))), time.Second + time.Second/3, false},
+		{"mvhd after other boxes", concat(ftyp, box("moov", box("iods", []byte{1, 2}), box("mvhd", mvhd(0, 600, 1200)))), 2 * time.Second, false},
+		{"64-bit moov", concat(ftyp, largeBox("moov", box("mvhd", mvhd(0, 1, 5)))), 5 * time.Second, false},
+		{"size 0 moov", concat(ftyp, openBox("moov", box("mvhd", mvhd(1, 1, 7)))), 7 * time.Second, false},
+		{"version 0 unknown", concat(ftyp, box("moov", box("mvhd", mvhd(0, 1000, math.MaxUint32)))), 0, false},
+		{"version 1 unknown", concat(ftyp, box("moov", box("mvhd", mvhd(1, 1000, math.MaxUint64)))), 0, false},
+		{"no timescale", concat(ftyp, box("moov", box("mvhd", mvhd(0, 0, 10)))), 0, true},
+		{"truncated mvhd", concat(ftyp, box("moov", box("mvhd", []byte{0, 0, 0, 0}))), 0, true},
+		{"unknown version", concat(ftyp, box("moov", box("mvhd", append([]byte{2}, make([]byte, 31)...)))), 0, true},
+		{"implausible", concat(ftyp, box("moov", box("mvhd", mvhd(1, 1, math.MaxUint64-1)))), 0, true},
+		{"no moov", ftyp, 0, true},
+		{"no mvhd", concat(ftyp, box("moov", box("trak"))), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mp4Duration(bytes.NewReader(tt.data), int64(len(tt.data)))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidVideo) {
+					t.Fatalf("err = %v, want ErrInvalidVideo", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("duration = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}